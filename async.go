@@ -0,0 +1,70 @@
+// Package memu provides fire-and-forget memorization for the MemU SDK.
+// This file implements background Memorize calls and draining them on shutdown.
+package memu
+
+import "context"
+
+// MemorizeAsyncResult is the outcome of a MemorizeAsync call.
+type MemorizeAsyncResult struct {
+	// Result is the memorization result, nil if Err is set.
+	Result *MemorizeResult
+	// Err is the error returned by Memorize, if any.
+	Err error
+}
+
+// MemorizeAsync submits a memorization request in a background goroutine and
+// returns immediately. The returned channel delivers exactly one result and is
+// then closed; callers that do not care about the outcome may ignore it.
+//
+// The request runs under an internal context that is independent of any
+// caller context, so it survives the end of e.g. an HTTP handler. The
+// internal context derives from the WithContext base context, if set. After
+// Shutdown has been called, the channel delivers ErrClientShutdown.
+func (c *Client) MemorizeAsync(req *MemorizeRequest) <-chan MemorizeAsyncResult {
+	ch := make(chan MemorizeAsyncResult, 1)
+
+	c.asyncMu.Lock()
+	if c.asyncClosed {
+		c.asyncMu.Unlock()
+		ch <- MemorizeAsyncResult{Err: ErrClientShutdown}
+		close(ch)
+		return ch
+	}
+	c.asyncWG.Add(1)
+	c.asyncMu.Unlock()
+
+	go func() {
+		defer c.asyncWG.Done()
+		result, err := c.Memorize(c.asyncCtx, req)
+		ch <- MemorizeAsyncResult{Result: result, Err: err}
+		close(ch)
+	}()
+
+	return ch
+}
+
+// Shutdown stops accepting MemorizeAsync work and waits for in-flight calls to
+// finish. If ctx is done first, in-flight calls are canceled and ctx.Err() is
+// returned. Shutdown does not affect synchronous methods.
+func (c *Client) Shutdown(ctx context.Context) error {
+	c.asyncMu.Lock()
+	c.asyncClosed = true
+	c.asyncMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.asyncWG.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		c.asyncCancel()
+		return nil
+	case <-ctx.Done():
+		// Abort the remaining work and wait for it to report
+		c.asyncCancel()
+		<-done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,135 @@
+// Package memu provides unit tests for fire-and-forget memorization.
+// This file validates MemorizeAsync result delivery and Shutdown draining.
+package memu
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newAsyncMemorizeRequest() *MemorizeRequest {
+	return &MemorizeRequest{
+		UserID:           "user_123",
+		AgentID:          "agent_456",
+		ConversationText: strPtr("user: I like tea"),
+	}
+}
+
+func TestMemorizeAsync_DeliversResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	select {
+	case res := <-client.MemorizeAsync(newAsyncMemorizeRequest()):
+		if res.Err != nil {
+			t.Fatalf("MemorizeAsync failed: %v", res.Err)
+		}
+		if res.Result.TaskID == nil || *res.Result.TaskID != "t1" {
+			t.Errorf("expected TaskID 't1', got %v", res.Result.TaskID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for async result")
+	}
+
+	// Validation errors are delivered on the channel too
+	res := <-client.MemorizeAsync(&MemorizeRequest{})
+	if res.Err == nil || res.Result != nil {
+		t.Errorf("expected validation error, got %+v", res)
+	}
+}
+
+func TestMemorizeAsync_ShutdownDrains(t *testing.T) {
+	var completed int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&completed, 1)
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	for i := 0; i < 3; i++ {
+		client.MemorizeAsync(newAsyncMemorizeRequest())
+	}
+
+	if err := client.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if got := atomic.LoadInt32(&completed); got != 3 {
+		t.Errorf("expected 3 drained requests, got %d", got)
+	}
+
+	res := <-client.MemorizeAsync(newAsyncMemorizeRequest())
+	if !errors.Is(res.Err, ErrClientShutdown) {
+		t.Errorf("expected ErrClientShutdown after shutdown, got %v", res.Err)
+	}
+}
+
+func TestMemorizeAsync_ShutdownDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithMaxRetries(0))
+	ch := client.MemorizeAsync(newAsyncMemorizeRequest())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := client.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// The in-flight call is canceled and still reports its outcome
+	if res := <-ch; res.Err == nil {
+		t.Error("expected canceled in-flight call to report an error")
+	}
+}
+
+func TestMemorizeAsync_BaseContextCanceled(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+			return
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	base, cancel := context.WithCancel(context.Background())
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithContext(base), WithRetryPolicy(NewNoRetryPolicy()))
+	ch := client.MemorizeAsync(newAsyncMemorizeRequest())
+	cancel()
+
+	select {
+	case res := <-ch:
+		if !errors.Is(res.Err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", res.Err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected async work to stop when the base context is canceled")
+	}
+
+	// Per-call contexts still govern direct calls
+	if _, err := client.GetTaskStatus(context.Background(), "t1"); err != nil {
+		t.Errorf("expected direct call to ignore the base context, got %v", err)
+	}
+}
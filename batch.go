@@ -0,0 +1,187 @@
+// Package memu provides batch helpers for the MemU SDK.
+// This file implements batch memorization, running many requests with bounded
+// concurrency and partitioning their outcomes.
+package memu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultBatchConcurrency is the number of concurrent Memorize calls used
+// when MemorizeBatch falls back to client-side fan-out.
+const DefaultBatchConcurrency = 4
+
+// BatchMemorizeResult holds the per-request outcomes of MemorizeBatch.
+// Results and Errors are in the same order as the requests; for each index
+// exactly one of the result and the error is non-nil.
+type BatchMemorizeResult struct {
+	// Results contains the memorize result of each successful request.
+	Results []*MemorizeResult
+	// Errors contains the error of each failed request.
+	Errors []error
+}
+
+// BatchRetrieve runs several retrieve requests with at most concurrency
+// requests in flight.
+//
+// Results and errors are returned in the same order as reqs; for each index
+// exactly one of the result and the error is non-nil. A failing query does not
+// affect the others. Once ctx is done, queries that have not started yet fail
+// with the context error. A concurrency below 1 runs the queries one at a time.
+func (c *Client) BatchRetrieve(ctx context.Context, reqs []*RetrieveRequest, concurrency int) ([]*RetrieveResult, []error) {
+	return runConcurrently(ctx, reqs, concurrency, c.Retrieve)
+}
+
+// MemorizeBatch memorizes several conversations with a single call to the
+// batch endpoint, which reports a task or an error for each conversation.
+// If the server has no batch endpoint (404), it falls back to calling
+// Memorize for each request with DefaultBatchConcurrency calls in flight.
+//
+// Every request is validated before anything is sent. The returned error is
+// reserved for failures of the batch as a whole; per-request failures are
+// reported in the result.
+func (c *Client) MemorizeBatch(ctx context.Context, reqs []*MemorizeRequest) (*BatchMemorizeResult, error) {
+	if len(reqs) == 0 {
+		return nil, invalidRequest(errors.New("MemorizeBatch: at least one request is required"))
+	}
+
+	payloads := make([]interface{}, len(reqs))
+	for i, req := range reqs {
+		if req == nil {
+			return nil, invalidRequest(fmt.Errorf("MemorizeBatch: request %d is nil", i))
+		}
+		payload, err := c.checkedMemorizePayload(req)
+		if err != nil {
+			return nil, fmt.Errorf("MemorizeBatch: request %d: %w", i, err)
+		}
+		payloads[i] = payload
+	}
+
+	// A replayed batch may create duplicate tasks
+	call := callOptions{operation: "MemorizeBatch", nonIdempotent: true}
+	response, err := c.request(ctx, "POST", c.apiPath("/memory/memorize/batch"), map[string]interface{}{"requests": payloads}, nil, call)
+	if err != nil {
+		var notFound *NotFoundError
+		if errors.As(err, &notFound) {
+			results, errs := runConcurrently(ctx, reqs, DefaultBatchConcurrency, c.Memorize)
+			return &BatchMemorizeResult{Results: results, Errors: errs}, nil
+		}
+		return nil, err
+	}
+
+	return parseBatchMemorizeResult(c.codec, response, len(reqs))
+}
+
+// parseBatchMemorizeResult parses the per-request "results" array of a batch
+// memorize response. Entries with an "error" field become errors.
+func parseBatchMemorizeResult(codec Codec, response map[string]interface{}, count int) (*BatchMemorizeResult, error) {
+	entries, ok := response["results"].([]interface{})
+	if !ok || len(entries) != count {
+		return nil, NewResponseValidationError("MemorizeBatch", fmt.Sprintf("expected %d results, got %v", count, response["results"]), response)
+	}
+
+	batch := &BatchMemorizeResult{
+		Results: make([]*MemorizeResult, count),
+		Errors:  make([]error, count),
+	}
+	for i, entry := range entries {
+		fields, ok := entry.(map[string]interface{})
+		if !ok {
+			batch.Errors[i] = fmt.Errorf("MemorizeBatch: result %d is not an object", i)
+			continue
+		}
+		if message, failed := batchEntryError(fields); failed {
+			batch.Errors[i] = NewClientError(message, nil, fields)
+			continue
+		}
+		result, err := parseMemorizeResult(codec, fields)
+		if err != nil {
+			batch.Errors[i] = err
+			continue
+		}
+		batch.Results[i] = result
+	}
+	return batch, nil
+}
+
+// batchEntryError returns the error message of a failed batch entry, whose
+// "error" field is either a message or an object with a "message".
+func batchEntryError(fields map[string]interface{}) (string, bool) {
+	switch e := fields["error"].(type) {
+	case string:
+		return e, true
+	case map[string]interface{}:
+		if message, ok := e["message"].(string); ok {
+			return message, true
+		}
+		return "memorize failed", true
+	default:
+		return "", false
+	}
+}
+
+// runConcurrently calls call for each request with at most concurrency calls
+// in flight, returning results and errors in the order of reqs. Requests not
+// yet started once ctx is done fail with the context error.
+func runConcurrently[Req, Res any](ctx context.Context, reqs []Req, concurrency int, call func(context.Context, Req) (Res, error)) ([]Res, []error) {
+	results := make([]Res, len(reqs))
+	errs := make([]error, len(reqs))
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		// Wait for a free slot unless the batch has been canceled
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			<-sem
+			errs[i] = err
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, req Req) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = call(ctx, req)
+		}(i, req)
+	}
+	wg.Wait()
+
+	return results, errs
+}
+
+// BatchFailure describes a failed request of a batch.
+type BatchFailure[Req any] struct {
+	// Index is the position of the request in the batch.
+	Index int
+	// Request is the request that failed.
+	Request Req
+	// Err is the error the request failed with.
+	Err error
+}
+
+// PartitionBatch splits the parallel results and errors of a batch call such
+// as BatchRetrieve into the successful results, in order, and the failures,
+// each carrying its original index and request so that only the failures
+// need to be retried. reqs, results and errs must have the same length.
+func PartitionBatch[Req, Res any](reqs []Req, results []Res, errs []error) (succeeded []Res, failed []BatchFailure[Req]) {
+	for i, req := range reqs {
+		if errs[i] != nil {
+			failed = append(failed, BatchFailure[Req]{Index: i, Request: req, Err: errs[i]})
+			continue
+		}
+		succeeded = append(succeeded, results[i])
+	}
+	return succeeded, failed
+}
@@ -0,0 +1,253 @@
+// Package memu provides unit tests for batch helpers.
+// This file validates BatchRetrieve ordering, error isolation and cancellation,
+// MemorizeBatch and its fallback, and partitioning batch outcomes.
+package memu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newEchoRetrieveServer returns a server echoing the query back as the
+// rewritten query. Queries equal to "fail" get a 500 response.
+func newEchoRetrieveServer(inFlight, maxInFlight *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(inFlight, 1)
+		defer atomic.AddInt32(inFlight, -1)
+		for {
+			max := atomic.LoadInt32(maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		query, _ := body["query"].(string)
+		if query == "fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"message":"boom"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"rewritten_query":%q}`, query)
+	}))
+}
+
+func newBatchRequests(queries ...string) []*RetrieveRequest {
+	reqs := make([]*RetrieveRequest, len(queries))
+	for i, query := range queries {
+		reqs[i] = &RetrieveRequest{UserID: "user_123", AgentID: "agent_456", Query: query}
+	}
+	return reqs
+}
+
+func TestBatchRetrieve_PreservesOrder(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := newEchoRetrieveServer(&inFlight, &maxInFlight)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	queries := []string{"q0", "q1", "q2", "q3", "q4", "q5", "q6", "q7"}
+	results, errs := client.BatchRetrieve(context.Background(), newBatchRequests(queries...), 3)
+
+	if len(results) != len(queries) || len(errs) != len(queries) {
+		t.Fatalf("expected %d results and errors, got %d and %d", len(queries), len(results), len(errs))
+	}
+	for i, query := range queries {
+		if errs[i] != nil {
+			t.Fatalf("query %d failed: %v", i, errs[i])
+		}
+		if results[i].GetRewrittenQuery() != query {
+			t.Errorf("expected result %d for %q, got %q", i, query, results[i].GetRewrittenQuery())
+		}
+	}
+	if max := atomic.LoadInt32(&maxInFlight); max > 3 {
+		t.Errorf("expected at most 3 requests in flight, got %d", max)
+	}
+}
+
+func TestBatchRetrieve_IsolatesErrors(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := newEchoRetrieveServer(&inFlight, &maxInFlight)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithMaxRetries(0))
+	reqs := newBatchRequests("q0", "fail", "q2")
+	reqs = append(reqs, &RetrieveRequest{UserID: "user_123", AgentID: "agent_456"})
+	results, errs := client.BatchRetrieve(context.Background(), reqs, 2)
+
+	if errs[0] != nil || errs[2] != nil {
+		t.Fatalf("expected successful queries to succeed, got %v and %v", errs[0], errs[2])
+	}
+	if results[0].GetRewrittenQuery() != "q0" || results[2].GetRewrittenQuery() != "q2" {
+		t.Errorf("unexpected results: %v, %v", results[0], results[2])
+	}
+	clientErr, ok := errs[1].(*ClientError)
+	if !ok || clientErr.StatusCode == nil || *clientErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected 500 ClientError for failing query, got %T: %v", errs[1], errs[1])
+	}
+	if errs[3] == nil {
+		t.Error("expected validation error for request without query")
+	}
+	if results[1] != nil || results[3] != nil {
+		t.Error("expected nil results for failed queries")
+	}
+}
+
+func TestBatchRetrieve_CanceledContext(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := newEchoRetrieveServer(&inFlight, &maxInFlight)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, errs := client.BatchRetrieve(ctx, newBatchRequests("q0", "q1"), 1)
+	for i := range errs {
+		if errs[i] != context.Canceled {
+			t.Errorf("expected context.Canceled for query %d, got %v", i, errs[i])
+		}
+		if results[i] != nil {
+			t.Errorf("expected nil result for query %d", i)
+		}
+	}
+	if atomic.LoadInt32(&maxInFlight) != 0 {
+		t.Error("expected no requests to be sent")
+	}
+}
+
+func TestPartitionBatch(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := newEchoRetrieveServer(&inFlight, &maxInFlight)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithMaxRetries(0))
+	reqs := newBatchRequests("q0", "fail", "q2", "fail")
+	results, errs := client.BatchRetrieve(context.Background(), reqs, 2)
+
+	succeeded, failed := PartitionBatch(reqs, results, errs)
+	if len(succeeded) != 2 || succeeded[0].GetRewrittenQuery() != "q0" || succeeded[1].GetRewrittenQuery() != "q2" {
+		t.Errorf("expected successes q0 and q2 in order, got %v", succeeded)
+	}
+	if len(failed) != 2 {
+		t.Fatalf("expected 2 failures, got %d", len(failed))
+	}
+	for i, index := range []int{1, 3} {
+		if failed[i].Index != index || failed[i].Request != reqs[index] || failed[i].Err == nil {
+			t.Errorf("failure %d: expected index %d with its request and error, got %+v", i, index, failed[i])
+		}
+	}
+
+	// Memorize results partition the same way
+	memorizeReqs := []*MemorizeRequest{newAsyncMemorizeRequest()}
+	ok, none := PartitionBatch(memorizeReqs, []*MemorizeResult{{TaskID: strPtr("t1")}}, []error{nil})
+	if len(ok) != 1 || none != nil {
+		t.Errorf("expected 1 success and no failures, got %v %v", ok, none)
+	}
+}
+
+func newBatchMemorizeRequests(n int) []*MemorizeRequest {
+	reqs := make([]*MemorizeRequest, n)
+	for i := range reqs {
+		reqs[i] = &MemorizeRequest{
+			UserID:           "user_123",
+			AgentID:          "agent_456",
+			ConversationText: strPtr(fmt.Sprintf("user: message %d", i)),
+		}
+	}
+	return reqs
+}
+
+func TestMemorizeBatch_Endpoint(t *testing.T) {
+	var paths []string
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		paths = append(paths, r.URL.Path)
+		bodies = append(bodies, body)
+		w.Write([]byte(`{"results":[{"task_id":"t0","status":"PENDING"},{"error":{"message":"conversation rejected"}},{"task_id":"t2","status":"PENDING"}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	batch, err := client.MemorizeBatch(context.Background(), newBatchMemorizeRequests(3))
+	if err != nil {
+		t.Fatalf("MemorizeBatch failed: %v", err)
+	}
+
+	if len(paths) != 1 || paths[0] != "/api/v3/memory/memorize/batch" {
+		t.Fatalf("expected a single batch request, got %v", paths)
+	}
+	if requests, ok := bodies[0]["requests"].([]interface{}); !ok || len(requests) != 3 {
+		t.Errorf("expected 3 conversations in the payload, got %v", bodies[0]["requests"])
+	}
+	if batch.Results[0] == nil || *batch.Results[0].TaskID != "t0" || batch.Errors[0] != nil {
+		t.Errorf("expected task t0 for request 0, got %v %v", batch.Results[0], batch.Errors[0])
+	}
+	if batch.Results[1] != nil || batch.Errors[1] == nil || !strings.Contains(batch.Errors[1].Error(), "conversation rejected") {
+		t.Errorf("expected error for request 1, got %v %v", batch.Results[1], batch.Errors[1])
+	}
+	if batch.Results[2] == nil || *batch.Results[2].TaskID != "t2" {
+		t.Errorf("expected task t2 for request 2, got %v", batch.Results[2])
+	}
+
+	// Invalid requests fail the batch before anything is sent
+	reqs := newBatchMemorizeRequests(2)
+	reqs[1].UserID = ""
+	if _, err := client.MemorizeBatch(context.Background(), reqs); err == nil || !strings.Contains(err.Error(), "request 1") {
+		t.Errorf("expected validation error for request 1, got %v", err)
+	}
+	if len(paths) != 1 {
+		t.Errorf("expected no request for an invalid batch, got %d", len(paths))
+	}
+}
+
+func TestMemorizeBatch_Fallback(t *testing.T) {
+	var batchCalls, singleCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/memory/memorize/batch" {
+			atomic.AddInt32(&batchCalls, 1)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		atomic.AddInt32(&singleCalls, 1)
+		if body["conversation_text"] == "user: message 1" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":"bad conversation"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"task_id":%q,"status":"PENDING"}`, body["conversation_text"])
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	batch, err := client.MemorizeBatch(context.Background(), newBatchMemorizeRequests(3))
+	if err != nil {
+		t.Fatalf("MemorizeBatch failed: %v", err)
+	}
+
+	if batchCalls != 1 || singleCalls != 3 {
+		t.Errorf("expected 1 batch call and 3 fallback calls, got %d and %d", batchCalls, singleCalls)
+	}
+	for _, i := range []int{0, 2} {
+		expected := fmt.Sprintf("user: message %d", i)
+		if batch.Results[i] == nil || *batch.Results[i].TaskID != expected || batch.Errors[i] != nil {
+			t.Errorf("expected task %q for request %d, got %v %v", expected, i, batch.Results[i], batch.Errors[i])
+		}
+	}
+	if batch.Results[1] != nil || batch.Errors[1] == nil {
+		t.Errorf("expected error for request 1, got %v %v", batch.Results[1], batch.Errors[1])
+	}
+}
@@ -0,0 +1,89 @@
+// Package memu provides request canonicalization for the MemU SDK.
+// This file implements validating and defaulting a MemorizeRequest in one call.
+package memu
+
+const (
+	// defaultUserName is the user name sent when MemorizeRequest.UserName is empty.
+	defaultUserName = "User"
+	// defaultAgentName is the agent name sent when MemorizeRequest.AgentName is empty.
+	defaultAgentName = "Assistant"
+)
+
+// CanonicalOption is a function that configures MemorizeRequest.Canonical.
+type CanonicalOption func(*canonicalConfig)
+
+// canonicalConfig holds the defaults applied by MemorizeRequest.Canonical.
+type canonicalConfig struct {
+	// userName replaces an empty UserName.
+	userName string
+	// agentName replaces an empty AgentName.
+	agentName string
+	// sessionDate replaces a nil SessionDate; empty leaves it unset.
+	sessionDate string
+}
+
+// newCanonicalConfig returns the defaults the SDK applies before sending.
+func newCanonicalConfig() canonicalConfig {
+	return canonicalConfig{
+		userName:  defaultUserName,
+		agentName: defaultAgentName,
+	}
+}
+
+// WithDefaultNames sets the user and agent names used when the request leaves
+// them empty, instead of "User" and "Assistant". Empty arguments keep the SDK
+// defaults.
+func WithDefaultNames(userName, agentName string) CanonicalOption {
+	return func(c *canonicalConfig) {
+		if userName != "" {
+			c.userName = userName
+		}
+		if agentName != "" {
+			c.agentName = agentName
+		}
+	}
+}
+
+// WithDefaultSessionDate sets the session date, in ISO 8601 format, used when
+// the request has no SessionDate. By default SessionDate stays unset.
+func WithDefaultSessionDate(date string) CanonicalOption {
+	return func(c *canonicalConfig) {
+		c.sessionDate = date
+	}
+}
+
+// Canonical validates the request and returns a copy with defaults applied:
+// empty UserName and AgentName are filled in and, with WithDefaultSessionDate,
+// a missing SessionDate is set. The result is what Memorize sends. The
+// receiver is not modified.
+func (r *MemorizeRequest) Canonical(opts ...CanonicalOption) (*MemorizeRequest, error) {
+	cfg := newCanonicalConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	canonical := r.withDefaults(cfg)
+	if err := canonical.Validate(); err != nil {
+		return nil, err
+	}
+	return canonical, nil
+}
+
+// withDefaults returns a copy of the request with the defaults of cfg applied.
+// The conversation is copied so later changes to either request do not leak.
+func (r *MemorizeRequest) withDefaults(cfg canonicalConfig) *MemorizeRequest {
+	canonical := *r
+	if r.Conversation != nil {
+		canonical.Conversation = append([]ConversationMessage(nil), r.Conversation...)
+	}
+	if canonical.UserName == "" {
+		canonical.UserName = cfg.userName
+	}
+	if canonical.AgentName == "" {
+		canonical.AgentName = cfg.agentName
+	}
+	if canonical.SessionDate == nil && cfg.sessionDate != "" {
+		date := cfg.sessionDate
+		canonical.SessionDate = &date
+	}
+	return &canonical
+}
@@ -0,0 +1,99 @@
+// Package memu provides unit tests for request canonicalization.
+// This file validates MemorizeRequest.Canonical defaults and validation.
+package memu
+
+import (
+	"strings"
+	"testing"
+)
+
+func newCanonicalTestRequest() *MemorizeRequest {
+	return &MemorizeRequest{
+		UserID:  "user_123",
+		AgentID: "agent_456",
+		Conversation: []ConversationMessage{
+			{Role: "user", Content: "I like tea"},
+			{Role: "assistant", Content: "Green or black?"},
+			{Role: "user", Content: "Green"},
+		},
+	}
+}
+
+func TestMemorizeRequest_Canonical_Defaults(t *testing.T) {
+	req := newCanonicalTestRequest()
+	req.UserName = ""
+	req.AgentName = ""
+
+	canonical, err := req.Canonical()
+	if err != nil {
+		t.Fatalf("Canonical failed: %v", err)
+	}
+	if canonical.UserName != "User" || canonical.AgentName != "Assistant" {
+		t.Errorf("expected default names, got %q and %q", canonical.UserName, canonical.AgentName)
+	}
+	if canonical.SessionDate != nil {
+		t.Errorf("expected SessionDate to stay unset, got %q", *canonical.SessionDate)
+	}
+
+	canonical, err = req.Canonical(WithDefaultNames("Alice", ""), WithDefaultSessionDate("2024-01-15"))
+	if err != nil {
+		t.Fatalf("Canonical failed: %v", err)
+	}
+	if canonical.UserName != "Alice" || canonical.AgentName != "Assistant" {
+		t.Errorf("expected custom user name and default agent name, got %q and %q", canonical.UserName, canonical.AgentName)
+	}
+	if canonical.SessionDate == nil || *canonical.SessionDate != "2024-01-15" {
+		t.Errorf("expected default SessionDate, got %v", canonical.SessionDate)
+	}
+}
+
+func TestMemorizeRequest_Canonical_KeepsSetValues(t *testing.T) {
+	req := newCanonicalTestRequest()
+	req.UserName = "Bob"
+	req.AgentName = "Helper"
+	req.SessionDate = strPtr("2023-06-01")
+
+	canonical, err := req.Canonical(WithDefaultNames("Alice", "Bot"), WithDefaultSessionDate("2024-01-15"))
+	if err != nil {
+		t.Fatalf("Canonical failed: %v", err)
+	}
+	if canonical.UserName != "Bob" || canonical.AgentName != "Helper" || *canonical.SessionDate != "2023-06-01" {
+		t.Errorf("expected set values to be kept, got %q, %q, %q", canonical.UserName, canonical.AgentName, *canonical.SessionDate)
+	}
+}
+
+func TestMemorizeRequest_Canonical_DoesNotMutate(t *testing.T) {
+	req := newCanonicalTestRequest()
+	req.UserName = ""
+
+	canonical, err := req.Canonical(WithDefaultSessionDate("2024-01-15"))
+	if err != nil {
+		t.Fatalf("Canonical failed: %v", err)
+	}
+	if req.UserName != "" || req.SessionDate != nil {
+		t.Errorf("expected the original request to be unchanged, got %+v", req)
+	}
+	canonical.Conversation[0].Content = "changed"
+	if req.Conversation[0].Content == "changed" {
+		t.Error("expected the conversation to be copied")
+	}
+}
+
+func TestMemorizeRequest_Canonical_Validates(t *testing.T) {
+	req := newCanonicalTestRequest()
+	req.Conversation = req.Conversation[:2]
+
+	canonical, err := req.Canonical()
+	if err == nil || !strings.Contains(err.Error(), "at least 3") {
+		t.Errorf("expected validation error, got %v", err)
+	}
+	if canonical != nil {
+		t.Errorf("expected no request on error, got %+v", canonical)
+	}
+
+	req = newCanonicalTestRequest()
+	req.UserID = ""
+	if _, err := req.Canonical(WithDefaultNames("Alice", "Bot")); err == nil || !strings.Contains(err.Error(), "UserID is required") {
+		t.Errorf("expected UserID error, got %v", err)
+	}
+}
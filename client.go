@@ -1,17 +1,27 @@
 // Package memu provides the core HTTP client implementation for the MemU SDK.
-// This is the main entry point for the SDK, implementing all four major API methods
+// This is the main entry point for the SDK, implementing all major API methods
 // with automatic retry logic, JSON parsing, and parameter validation.
 package memu
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"math/rand"
+	"mime"
+	"net"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -24,16 +34,32 @@ const (
 	DefaultPollInterval = 2 * time.Second
 	// DefaultMaxRetries is the default maximum number of retry attempts.
 	DefaultMaxRetries = 3
+	// DefaultMaxResponseBytes is the default maximum decoded response body size.
+	DefaultMaxResponseBytes = 32 << 20
+	// DefaultAPIVersion is the default API version used in request paths.
+	DefaultAPIVersion = "v3"
 	// DefaultWaitTimeout is the default maximum time to wait for task completion.
 	DefaultWaitTimeout = 5 * time.Minute
 )
 
+// regionBaseURLs maps known region names to their API base URLs.
+var regionBaseURLs = map[string]string{
+	"us": DefaultBaseURL,
+	"eu": "https://eu.api.memu.so",
+}
+
 // Client is the MemU API client.
 type Client struct {
+	// name labels the client in hooks, logs and metrics; empty by default.
+	name string
 	// apiKey is the API authentication key.
 	apiKey string
+	// apiKeyMu guards apiKey, which may be replaced by token refresh.
+	apiKeyMu sync.RWMutex
 	// baseURL is the base URL for API requests.
 	baseURL string
+	// apiVersion is the API version used in the /api/<version>/ path prefix.
+	apiVersion string
 	// httpClient is the underlying HTTP client used for requests.
 	httpClient *http.Client
 	// maxRetries is the maximum number of retry attempts.
@@ -42,6 +68,112 @@ type Client struct {
 	timeout time.Duration
 	// retryPolicy defines the retry behavior for failed requests.
 	retryPolicy RetryPolicy
+	// requestIDExtractor extracts a correlation ID from the request context.
+	requestIDExtractor func(ctx context.Context) string
+	// validatePayloadSchema enables schema validation of built payloads.
+	validatePayloadSchema bool
+	// maxRequestBytes is the maximum allowed request body size (0 means unlimited).
+	maxRequestBytes int64
+	// maxResponseBytes is the maximum allowed decoded response body size (0 means unlimited).
+	maxResponseBytes int64
+	// retryOnTimeout controls whether transport-level timeouts are retried.
+	retryOnTimeout bool
+	// rejectDuplicateMessages rejects conversations with consecutive duplicate messages.
+	rejectDuplicateMessages bool
+	// requireChronologicalMessages rejects conversations whose CreatedAt timestamps decrease.
+	requireChronologicalMessages bool
+	// maxConversationMessages caps the Memorize conversation length; 0 means no cap.
+	maxConversationMessages int
+	// rejectFutureSessionDate rejects session dates later than now plus futureSessionDateTolerance.
+	rejectFutureSessionDate bool
+	// futureSessionDateTolerance is how far ahead of now a session date may be.
+	futureSessionDateTolerance time.Duration
+	// timeFormat is an extra layout accepted when parsing timestamps.
+	timeFormat string
+	// now returns the current time. It defaults to time.Now.
+	now func() time.Time
+	// headerFunc returns extra headers evaluated on every request attempt.
+	headerFunc func(ctx context.Context, method, path string) map[string]string
+	// allowAuthOverride lets headerFunc replace the Authorization header.
+	allowAuthOverride bool
+	// bodyRetryPredicate reports whether a successful response body signals a transient failure.
+	bodyRetryPredicate func(body map[string]interface{}) bool
+	// taskStatusMemo enables serving terminal task statuses from terminalTasks.
+	taskStatusMemo bool
+	// terminalTasks memoizes terminal task statuses by task ID, evicting the oldest.
+	terminalTasks *taskStatusMemo
+	// metrics receives request and retry observations.
+	metrics Metrics
+	// tokenRefresh obtains a new API key after a 401 response.
+	tokenRefresh func(ctx context.Context) (string, error)
+	// queryAsConversation wraps string Retrieve queries into a single user message.
+	queryAsConversation bool
+	// codec encodes request bodies and decodes response bodies.
+	codec Codec
+	// region selects a regional base URL unless baseURLSet is true.
+	region string
+	// baseURLSet reports whether WithBaseURL was used.
+	baseURLSet bool
+	// retryableErrorMessages are lowercase substrings marking 4xx responses as transient.
+	retryableErrorMessages []string
+	// responseEnvelope is the key successful responses are wrapped in, if any.
+	responseEnvelope string
+	// retryNonIdempotent allows retrying non-idempotent calls after ambiguous transport errors.
+	retryNonIdempotent bool
+	// idNormalizer rewrites UserID and AgentID before they are sent; nil leaves them unchanged.
+	idNormalizer func(string) string
+	// retrieveFallbackQuery is retried by Retrieve when the primary query returns no items; nil disables it.
+	retrieveFallbackQuery interface{}
+	// roleAliases maps message roles to the roles sent; unmapped roles pass through.
+	roleAliases map[string]string
+	// treat403AsRateLimit handles 403 responses carrying Retry-After like 429.
+	treat403AsRateLimit bool
+	// tlsServerName overrides the server name used to verify TLS certificates.
+	tlsServerName string
+	// etags caches task statuses by ETag for conditional GetTaskStatus requests; nil disables it.
+	etags *etagCache
+	// methodTimeouts maps operation names to per-attempt timeouts overriding timeout.
+	methodTimeouts map[string]time.Duration
+	// lastRequestURL is the full URL of the most recent request attempt.
+	lastRequestURL string
+	// lastRequestURLMu guards lastRequestURL.
+	lastRequestURLMu sync.Mutex
+	// onRateLimit is called with the wait time whenever a rate limit response is received.
+	onRateLimit func(retryAfter time.Duration)
+	// connectTimeout bounds establishing a connection, including DNS resolution.
+	connectTimeout time.Duration
+	// tracer wraps each API call, e.g. in a tracing span; nil disables tracing.
+	tracer Tracer
+	// logger receives diagnostic lines; nil disables logging.
+	logger *log.Logger
+	// retrieveLenient skips unparseable array elements in Retrieve responses instead of failing.
+	retrieveLenient bool
+	// skipValidation bypasses request Validate calls, trusting the caller.
+	skipValidation bool
+	// autoPaginateMaxItems caps the items collected by following Retrieve cursors; 0 disables paging.
+	autoPaginateMaxItems int
+	// maxConcurrentRequests caps the API calls in flight at once; 0 means no cap.
+	maxConcurrentRequests int
+	// requestSlots is a semaphore holding one token per call in flight; nil when uncapped.
+	requestSlots chan struct{}
+	// startupJitter is the maximum random delay before the first request.
+	startupJitter time.Duration
+	// startupOnce picks startupAt on the first request.
+	startupOnce sync.Once
+	// startupAt is the time before which no request is sent.
+	startupAt time.Time
+	// baseCtx is the parent of asyncCtx; it defaults to context.Background.
+	baseCtx context.Context
+	// asyncCtx is the internal context for MemorizeAsync work, canceled by Shutdown.
+	asyncCtx context.Context
+	// asyncCancel cancels asyncCtx.
+	asyncCancel context.CancelFunc
+	// asyncMu guards asyncClosed and additions to asyncWG.
+	asyncMu sync.Mutex
+	// asyncClosed reports whether Shutdown has been called.
+	asyncClosed bool
+	// asyncWG tracks in-flight MemorizeAsync work.
+	asyncWG sync.WaitGroup
 }
 
 // NewClient creates a new MemU API client.
@@ -54,12 +186,20 @@ func NewClient(apiKey string, opts ...Option) (*Client, error) {
 	client := &Client{
 		apiKey:     apiKey,
 		baseURL:    strings.TrimRight(DefaultBaseURL, "/"),
+		apiVersion: DefaultAPIVersion,
 		maxRetries: DefaultMaxRetries,
 		timeout:    DefaultTimeout,
 		httpClient: &http.Client{
 			Timeout: DefaultTimeout,
 		},
-		retryPolicy: NewDefaultRetryPolicy(nil),
+		retryPolicy:      NewDefaultRetryPolicy(nil),
+		retryOnTimeout:   true,
+		maxResponseBytes: DefaultMaxResponseBytes,
+		taskStatusMemo:   true,
+		terminalTasks:    newTaskStatusMemo(DefaultTaskStatusMemoSize),
+		now:              time.Now,
+		codec:            defaultCodec,
+		baseCtx:          context.Background(),
 	}
 
 	// Apply options
@@ -67,19 +207,169 @@ func NewClient(apiKey string, opts ...Option) (*Client, error) {
 		opt(client)
 	}
 
+	// Resolve the region; an explicit base URL takes precedence
+	if client.region != "" {
+		regionURL, ok := regionBaseURLs[strings.ToLower(client.region)]
+		if !ok {
+			return nil, fmt.Errorf("unknown region %q", client.region)
+		}
+		if !client.baseURLSet {
+			client.baseURL = regionURL
+		}
+	}
+
+	if err := validateBaseURL(client.baseURL); err != nil {
+		return nil, err
+	}
+	if client.apiVersion == "" || strings.Contains(client.apiVersion, "/") {
+		return nil, fmt.Errorf("invalid API version %q", client.apiVersion)
+	}
+
+	client.asyncCtx, client.asyncCancel = context.WithCancel(client.baseCtx)
+
+	if client.maxConcurrentRequests > 0 {
+		client.requestSlots = make(chan struct{}, client.maxConcurrentRequests)
+	}
+
+	if client.tlsServerName != "" || client.connectTimeout > 0 {
+		if err := client.applyTransportOptions(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update HTTP client timeout if it was changed
 	if client.httpClient.Timeout != client.timeout {
 		client.httpClient.Timeout = client.timeout
 	}
+	// Per-operation timeouts are enforced per attempt by attemptContext
+	if len(client.methodTimeouts) > 0 {
+		client.httpClient.Timeout = 0
+	}
 
 	return client, nil
 }
 
+// validateBaseURL checks that baseURL is an absolute http or https URL with
+// a host, so that typos fail in NewClient rather than on the first request.
+func validateBaseURL(baseURL string) error {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("invalid base URL %q: scheme must be http or https", baseURL)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("invalid base URL %q: host is required", baseURL)
+	}
+	return nil
+}
+
+// applyTransportOptions applies tlsServerName and connectTimeout to the HTTP
+// client's transport. The transport is cloned so that shared transports,
+// including http.DefaultTransport, are left unchanged.
+func (c *Client) applyTransportOptions() error {
+	var transport *http.Transport
+	switch t := c.httpClient.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return fmt.Errorf("WithTLSServerName and WithConnectTimeout require an *http.Transport, got %T", t)
+	}
+	if c.tlsServerName != "" {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.ServerName = c.tlsServerName
+	}
+	if c.connectTimeout > 0 {
+		dialer := &net.Dialer{Timeout: c.connectTimeout, KeepAlive: 30 * time.Second}
+		transport.DialContext = dialer.DialContext
+	}
+
+	httpClient := *c.httpClient
+	httpClient.Transport = transport
+	c.httpClient = &httpClient
+	return nil
+}
+
+// ClientConfig describes the effective configuration of a Client.
+// It is safe to log: the API key is masked.
+type ClientConfig struct {
+	// Name is the client label set with WithName.
+	Name string
+	// BaseURL is the base URL for API requests.
+	BaseURL string
+	// Timeout is the request timeout duration.
+	Timeout time.Duration
+	// MaxRetries is the maximum number of retry attempts.
+	MaxRetries int
+	// RetryPolicy is the type name of the active retry policy.
+	RetryPolicy string
+	// APIKeySet reports whether an API key is configured.
+	APIKeySet bool
+	// MaskedAPIKey is the API key with everything after its prefix masked (e.g., "mu_****").
+	MaskedAPIKey string
+}
+
+// Config returns the effective client configuration for diagnostics.
+func (c *Client) Config() ClientConfig {
+	apiKey := c.currentAPIKey()
+	return ClientConfig{
+		Name:         c.name,
+		BaseURL:      c.baseURL,
+		Timeout:      c.timeout,
+		MaxRetries:   c.maxRetries,
+		RetryPolicy:  fmt.Sprintf("%T", c.retryPolicy),
+		APIKeySet:    apiKey != "",
+		MaskedAPIKey: maskAPIKey(apiKey),
+	}
+}
+
+// maskAPIKey masks an API key, keeping only a short underscore-terminated
+// prefix such as "mu_".
+func maskAPIKey(apiKey string) string {
+	if apiKey == "" {
+		return ""
+	}
+	if idx := strings.Index(apiKey, "_"); idx >= 0 && idx < 4 && idx < len(apiKey)-1 {
+		return apiKey[:idx+1] + "****"
+	}
+	return "****"
+}
+
+// currentAPIKey returns the API key currently used for authentication.
+func (c *Client) currentAPIKey() string {
+	c.apiKeyMu.RLock()
+	defer c.apiKeyMu.RUnlock()
+	return c.apiKey
+}
+
+// refreshAPIKey obtains a new API key from the token refresh callback and
+// installs it. It reports whether a usable key was obtained.
+func (c *Client) refreshAPIKey(ctx context.Context) bool {
+	apiKey, err := c.tokenRefresh(ctx)
+	if err != nil {
+		return false
+	}
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == "" {
+		return false
+	}
+
+	c.apiKeyMu.Lock()
+	c.apiKey = apiKey
+	c.apiKeyMu.Unlock()
+	return true
+}
+
 // defaultHeaders returns the default headers for API requests.
 // This includes the authorization bearer token, content type, and user agent.
 func (c *Client) defaultHeaders() map[string]string {
 	return map[string]string{
-		"Authorization": fmt.Sprintf("Bearer %s", c.apiKey),
+		"Authorization": fmt.Sprintf("Bearer %s", c.currentAPIKey()),
 		"Content-Type":  "application/json",
 		"User-Agent":    "memu-go-sdk/1.0.0",
 	}
@@ -89,18 +379,18 @@ func (c *Client) defaultHeaders() map[string]string {
 // This is a performance optimization that directly deserializes data without
 // the overhead of Marshal → Unmarshal cycles.
 // It accepts any interface and returns a pointer to the typed struct.
-func parseJSONObject[T any](data interface{}) (*T, error) {
+func parseJSONObject[T any](codec Codec, data interface{}) (*T, error) {
 	if data == nil {
 		return nil, nil
 	}
 
-	jsonBytes, err := json.Marshal(data)
+	jsonBytes, err := codec.Marshal(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal object: %w", err)
 	}
 
 	var obj T
-	if err := json.Unmarshal(jsonBytes, &obj); err != nil {
+	if err := codec.Unmarshal(jsonBytes, &obj); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal object: %w", err)
 	}
 
@@ -111,269 +401,125 @@ func parseJSONObject[T any](data interface{}) (*T, error) {
 // This is a performance optimization that serializes the entire array at once
 // rather than processing elements individually.
 // It accepts a slice of interfaces and returns a slice of pointers to the typed struct.
-func parseJSONArray[T any](data []interface{}) ([]*T, error) {
+func parseJSONArray[T any](codec Codec, data []interface{}) ([]*T, error) {
 	if len(data) == 0 {
 		return nil, nil
 	}
 
 	// Marshal the entire array once
-	jsonBytes, err := json.Marshal(data)
+	jsonBytes, err := codec.Marshal(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal array: %w", err)
 	}
 
 	// Unmarshal into the target type
 	var result []*T
-	if err := json.Unmarshal(jsonBytes, &result); err != nil {
+	if err := codec.Unmarshal(jsonBytes, &result); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal array: %w", err)
 	}
 
 	return result, nil
 }
 
-// buildMemorizePayload builds the payload for a Memorize request.
-// This provides unified payload construction logic to simplify the Memorize method.
-// It handles default values for user_name and agent_name, and conditionally includes
-// conversation, conversation_text, and session_date fields.
-func buildMemorizePayload(req *MemorizeRequest) map[string]interface{} {
-	payload := map[string]interface{}{
-		"user_id":  req.UserID,
-		"agent_id": req.AgentID,
+// parseResponseBody parses a raw response body into a JSON object.
+// Bodies that are not a JSON object (invalid JSON, arrays, scalars) are
+// returned under the "raw" key instead of failing, so hostile or unexpected
+// input never aborts the request. An empty body yields a nil map.
+func parseResponseBody(codec Codec, body []byte) map[string]interface{} {
+	if len(body) == 0 {
+		return nil
 	}
 
-	if req.UserName != "" {
-		payload["user_name"] = req.UserName
-	} else {
-		payload["user_name"] = "User"
+	var result map[string]interface{}
+	if err := codec.Unmarshal(body, &result); err != nil || result == nil {
+		// If JSON parsing fails, return the raw response
+		return map[string]interface{}{
+			"raw": string(body),
+		}
 	}
+	return result
+}
 
-	if req.AgentName != "" {
-		payload["agent_name"] = req.AgentName
-	} else {
-		payload["agent_name"] = "Assistant"
+// parseMemorizeResult parses a Memorize response.
+// Extracted items are only present in sync mode responses.
+func parseMemorizeResult(codec Codec, response map[string]interface{}) (*MemorizeResult, error) {
+	result := &MemorizeResult{}
+	if taskID, ok := response["task_id"].(string); ok {
+		result.TaskID = &taskID
 	}
-
-	if len(req.Conversation) > 0 {
-		payload["conversation"] = req.Conversation
-	} else if req.ConversationText != nil {
-		payload["conversation_text"] = *req.ConversationText
+	if status, ok := response["status"].(string); ok {
+		result.Status = &status
 	}
-
-	if req.SessionDate != nil {
-		payload["session_date"] = *req.SessionDate
+	if message, ok := response["message"].(string); ok {
+		result.Message = &message
 	}
 
-	return payload
-}
-
-// request makes an HTTP request to the API with automatic retry logic.
-// It handles request construction, header setting, query parameters, response parsing,
-// rate limiting, and error handling. The method automatically retries on transient errors
-// based on the configured retry policy.
-func (c *Client) request(ctx context.Context, method, path string, body interface{}, params map[string]string) (map[string]interface{}, error) {
-	for attempt := 0; ; attempt++ {
-		// Prepare request body
-		var bodyReader io.Reader
-		if body != nil {
-			jsonData, err := json.Marshal(body)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal request body: %w", err)
-			}
-			bodyReader = bytes.NewReader(jsonData)
-		}
-
-		// Create request
-		url := c.baseURL + path
-		req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
-		}
-
-		// Set headers
-		for key, value := range c.defaultHeaders() {
-			req.Header.Set(key, value)
-		}
-
-		// Set query parameters
-		if len(params) > 0 {
-			q := req.URL.Query()
-			for key, value := range params {
-				q.Add(key, value)
-			}
-			req.URL.RawQuery = q.Encode()
-		}
-
-		// Make request
-		resp, err := c.httpClient.Do(req)
+	if items, ok := response["items"].([]interface{}); ok {
+		parsedItems, err := parseJSONArray[MemoryItem](codec, items)
 		if err != nil {
-			// Check if we should retry
-			if c.retryPolicy.ShouldRetry(attempt, 0, err) {
-				time.Sleep(c.retryPolicy.GetBackoff(attempt))
-				continue
-			}
-			return nil, fmt.Errorf("request failed after %d attempts: %w", attempt+1, err)
+			return nil, fmt.Errorf("failed to parse items: %w", err)
 		}
-		defer resp.Body.Close()
+		result.Items = parsedItems
+	}
 
-		// Read response body
-		respBody, err := io.ReadAll(resp.Body)
+	if categories, ok := response["categories"].([]interface{}); ok {
+		parsedCategories, err := parseJSONArray[MemoryCategory](codec, categories)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read response body: %w", err)
-		}
-
-		// Parse response
-		var result map[string]interface{}
-		if len(respBody) > 0 {
-			if err := json.Unmarshal(respBody, &result); err != nil {
-				// If JSON parsing fails, return the raw response
-				result = map[string]interface{}{
-					"raw": string(respBody),
-				}
-			}
+			return nil, fmt.Errorf("failed to parse categories: %w", err)
 		}
+		result.Categories = parsedCategories
+	}
 
-		// Handle rate limiting (429)
-		if resp.StatusCode == http.StatusTooManyRequests {
-			retryAfter := resp.Header.Get("Retry-After")
-			var waitTime time.Duration
-			if retryAfter != "" {
-				if seconds, err := strconv.ParseFloat(retryAfter, 64); err == nil {
-					waitTime = time.Duration(seconds * float64(time.Second))
-				}
-			} else {
-				waitTime = c.retryPolicy.GetBackoff(attempt)
-			}
-
-			if c.retryPolicy.ShouldRetry(attempt, resp.StatusCode, nil) {
-				time.Sleep(waitTime)
-				continue
-			}
+	result.Warnings = parseWarnings(response["warnings"])
 
-			retryAfterFloat := float64(waitTime) / float64(time.Second)
-			statusCode := resp.StatusCode
-			return nil, NewRateLimitError("rate limit exceeded", &retryAfterFloat, &statusCode, result)
-		}
+	return result, nil
+}
 
-		// Handle server errors (5xx) - retry
-		if resp.StatusCode >= 500 {
-			if c.retryPolicy.ShouldRetry(attempt, resp.StatusCode, nil) {
-				time.Sleep(c.retryPolicy.GetBackoff(attempt))
-				continue
+// parseWarnings parses a "warnings" array of strings, or of objects carrying
+// the text under "message". Other entries are ignored.
+func parseWarnings(value interface{}) []string {
+	entries, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	var warnings []string
+	for _, entry := range entries {
+		switch v := entry.(type) {
+		case string:
+			if v != "" {
+				warnings = append(warnings, v)
 			}
-			statusCode := resp.StatusCode
-			// Include response body in error message for debugging
-			errorMsg := fmt.Sprintf("server error: %d", resp.StatusCode)
-			if len(respBody) > 0 {
-				errorMsg = fmt.Sprintf("server error: %d, response: %s", resp.StatusCode, string(respBody))
+		case map[string]interface{}:
+			if msg, ok := v["message"].(string); ok && msg != "" {
+				warnings = append(warnings, msg)
 			}
-			return nil, NewClientError(errorMsg, &statusCode, result)
 		}
-
-		// Handle client errors (4xx) - don't retry
-		if resp.StatusCode >= 400 {
-			return nil, c.raiseForStatus(resp.StatusCode, path, result)
-		}
-
-		// Success
-		return result, nil
-	}
-}
-
-// raiseForStatus raises an appropriate error for HTTP error status codes.
-// It maps HTTP status codes to specific error types: 401 to AuthenticationError,
-// 404 to NotFoundError, 422 to ValidationError, and others to generic ClientError.
-func (c *Client) raiseForStatus(statusCode int, path string, response map[string]interface{}) error {
-	status := &statusCode
-
-	switch statusCode {
-	case http.StatusUnauthorized:
-		return NewAuthenticationError(status, response)
-	case http.StatusNotFound:
-		return NewNotFoundError(path, status, response)
-	case http.StatusUnprocessableEntity:
-		return NewValidationError(status, response)
-	default:
-		return NewClientError(fmt.Sprintf("HTTP %d: %s", statusCode, path), status, response)
 	}
+	return warnings
 }
 
-// Memorize memorizes a conversation and extracts structured memory.
-func (c *Client) Memorize(ctx context.Context, req *MemorizeRequest) (*MemorizeResult, error) {
-	if req == nil {
-		return nil, fmt.Errorf("Memorize: request is required")
-	}
-
-	if err := req.Validate(); err != nil {
-		return nil, err
-	}
-
-	// Build request payload
-	payload := buildMemorizePayload(req)
-
-	// Make request
-	response, err := c.request(ctx, "POST", "/api/v3/memory/memorize", payload, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	// Parse response
-	result := &MemorizeResult{}
-	if taskID, ok := response["task_id"].(string); ok {
-		result.TaskID = &taskID
-	}
-	if status, ok := response["status"].(string); ok {
-		result.Status = &status
-	}
-	if message, ok := response["message"].(string); ok {
-		result.Message = &message
+// logWarnings writes each response warning of operation to the configured logger, if any.
+func (c *Client) logWarnings(operation string, warnings []string) {
+	for _, warning := range warnings {
+		c.logf("%s: server warning: %s", operation, warning)
 	}
-
-	return result, nil
 }
 
-// GetTaskStatus gets the status of a memorization task.
-func (c *Client) GetTaskStatus(ctx context.Context, taskID string) (*TaskStatus, error) {
-	if taskID == "" {
-		return nil, fmt.Errorf("taskID is required")
-	}
-
-	path := fmt.Sprintf("/api/v3/memory/memorize/status/%s", taskID)
-	response, err := c.request(ctx, "GET", path, nil, nil)
-	if err != nil {
-		return nil, err
-	}
+// parseTaskStatus parses a GetTaskStatus response.
+func parseTaskStatus(codec Codec, response map[string]interface{}) (*TaskStatus, error) {
 	// Parse response using parseJSONObject to avoid double serialization
-	status, err := parseJSONObject[TaskStatus](response)
+	status, err := parseJSONObject[TaskStatus](codec, response)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse task status: %w", err)
 	}
-
+	if status == nil {
+		status = &TaskStatus{}
+	}
 	return status, nil
 }
 
-// ListCategories lists all memory categories.
-func (c *Client) ListCategories(ctx context.Context, req *ListCategoriesRequest) ([]*MemoryCategory, error) {
-	if req == nil {
-		return nil, fmt.Errorf("ListCategories: request is required")
-	}
-
-	if err := req.Validate(); err != nil {
-		return nil, err
-	}
-
-	// Build request payload
-	payload := map[string]interface{}{
-		"user_id":  req.UserID,
-		"agent_id": req.AgentID,
-	}
-
-	// Make request
-	response, err := c.request(ctx, "POST", "/api/v3/memory/categories", payload, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	// Parse response
+// parseCategories parses a ListCategories response.
+func parseCategories(codec Codec, response map[string]interface{}) ([]*MemoryCategory, error) {
 	var categories []*MemoryCategory
 
 	// Try to get categories from "categories" field first
@@ -384,7 +530,7 @@ func (c *Client) ListCategories(ctx context.Context, req *ListCategoriesRequest)
 	}
 
 	if categoriesList, ok := categoriesData.([]interface{}); ok {
-		parsedCategories, err := parseJSONArray[MemoryCategory](categoriesList)
+		parsedCategories, err := parseJSONArray[MemoryCategory](codec, categoriesList)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse categories: %w", err)
 		}
@@ -394,59 +540,1476 @@ func (c *Client) ListCategories(ctx context.Context, req *ListCategoriesRequest)
 	return categories, nil
 }
 
-// Retrieve retrieves relevant memories based on a query.
-func (c *Client) Retrieve(ctx context.Context, req *RetrieveRequest) (*RetrieveResult, error) {
-	if req == nil {
-		return nil, fmt.Errorf("Retrieve: request is required")
+// parseMemoryTypes parses a ListMemoryTypes response into a sorted,
+// de-duplicated slice. Types are read from "memory_types" and, for servers
+// that return memory items instead, from each item's "memory_type".
+func parseMemoryTypes(codec Codec, response map[string]interface{}) ([]string, error) {
+	seen := make(map[string]bool)
+	if types, ok := response["memory_types"].([]interface{}); ok {
+		for _, value := range types {
+			if memoryType, ok := value.(string); ok && memoryType != "" {
+				seen[memoryType] = true
+			}
+		}
 	}
-
-	if err := req.Validate(); err != nil {
-		return nil, err
+	if items, ok := response["items"].([]interface{}); ok {
+		parsedItems, err := parseJSONArray[MemoryItem](codec, items)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse items: %w", err)
+		}
+		for _, item := range parsedItems {
+			if item != nil && item.MemoryType != nil && *item.MemoryType != "" {
+				seen[*item.MemoryType] = true
+			}
+		}
 	}
 
-	// Build request payload
-	payload := map[string]interface{}{
-		"user_id":  req.UserID,
-		"agent_id": req.AgentID,
-		"query":    req.Query,
+	memoryTypes := make([]string, 0, len(seen))
+	for memoryType := range seen {
+		memoryTypes = append(memoryTypes, memoryType)
 	}
+	sort.Strings(memoryTypes)
+	return memoryTypes, nil
+}
 
-	// Make request
-	response, err := c.request(ctx, "POST", "/api/v3/memory/retrieve", payload, nil)
-	if err != nil {
-		return nil, err
-	}
+// parseRetrieveResult parses a Retrieve response.
+func parseRetrieveResult(codec Codec, response map[string]interface{}) (*RetrieveResult, error) {
+	return parseRetrieveResultWith(codec, response, nil)
+}
 
-	// Parse response
+// parseRetrieveResultWith parses a Retrieve response. If onDrop is non-nil,
+// array elements that fail to parse are skipped, counted in
+// RetrieveResult.DroppedElements and reported to onDrop instead of failing
+// the whole response.
+func parseRetrieveResultWith(codec Codec, response map[string]interface{}, onDrop func(key string, index int, err error)) (*RetrieveResult, error) {
 	result := &RetrieveResult{}
 
 	if categories, ok := response["categories"].([]interface{}); ok {
-		parsedCategories, err := parseJSONArray[MemoryCategory](categories)
+		parsedCategories, dropped, err := parseRetrieveArray[MemoryCategory](codec, "categories", categories, onDrop)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse categories: %w", err)
+			return nil, err
 		}
 		result.Categories = parsedCategories
+		result.DroppedElements += dropped
 	}
 
-	if items, ok := response["items"].([]interface{}); ok {
-		parsedItems, err := parseJSONArray[MemoryItem](items)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse items: %w", err)
+	// Some API versions return items under "memories"; merge both keys
+	for _, key := range []string{"items", "memories"} {
+		if items, ok := response[key].([]interface{}); ok {
+			parsedItems, dropped, err := parseRetrieveArray[MemoryItem](codec, key, items, onDrop)
+			if err != nil {
+				return nil, err
+			}
+			result.Items = append(result.Items, parsedItems...)
+			result.DroppedElements += dropped
 		}
-		result.Items = parsedItems
 	}
 
 	if resources, ok := response["resources"].([]interface{}); ok {
-		parsedResources, err := parseJSONArray[MemoryResource](resources)
+		parsedResources, dropped, err := parseRetrieveArray[MemoryResource](codec, "resources", resources, onDrop)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse resources: %w", err)
+			return nil, err
 		}
 		result.Resources = parsedResources
+		result.DroppedElements += dropped
 	}
 
-	if rewrittenQuery, ok := response["rewritten_query"].(string); ok {
+	if rewrittenQuery, ok := parseRewrittenQuery(response["rewritten_query"]); ok {
 		result.RewrittenQuery = &rewrittenQuery
 	}
 
+	if totalCount, ok := response["total_count"].(float64); ok {
+		total := int(totalCount)
+		result.TotalCount = &total
+	}
+
+	result.Warnings = parseWarnings(response["warnings"])
+
+	return result, nil
+}
+
+// parseRetrieveArray parses the array under key of a Retrieve response. With
+// a nil onDrop any bad element fails the array; otherwise bad elements are
+// skipped, reported to onDrop and counted in the returned dropped count.
+func parseRetrieveArray[T any](codec Codec, key string, data []interface{}, onDrop func(key string, index int, err error)) ([]*T, int, error) {
+	if onDrop == nil {
+		parsed, err := parseJSONArray[T](codec, data)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to parse %s: %w", key, err)
+		}
+		return parsed, 0, nil
+	}
+
+	parsed := make([]*T, 0, len(data))
+	dropped := 0
+	for i, element := range data {
+		value, err := parseJSONObject[T](codec, element)
+		if err != nil {
+			dropped++
+			onDrop(key, i, err)
+			continue
+		}
+		parsed = append(parsed, value)
+	}
+	return parsed, dropped, nil
+}
+
+// parseRetrieveResponse parses a Retrieve response, skipping and logging
+// unparseable array elements when WithRetrieveLenient is set.
+func (c *Client) parseRetrieveResponse(response map[string]interface{}) (*RetrieveResult, error) {
+	if !c.retrieveLenient {
+		return parseRetrieveResult(c.codec, response)
+	}
+	return parseRetrieveResultWith(c.codec, response, func(key string, index int, err error) {
+		c.logf("Retrieve: dropping unparseable %s[%d]: %v", key, index, err)
+	})
+}
+
+// logf writes a line to the configured logger, if any, prefixed with the
+// client name in brackets when WithName is set.
+func (c *Client) logf(format string, args ...interface{}) {
+	if c.logger == nil {
+		return
+	}
+	if c.name != "" {
+		format = "[" + c.name + "] " + format
+	}
+	c.logger.Printf(format, args...)
+}
+
+// clientNameKey is the context key under which requests carry the client name.
+type clientNameKey struct{}
+
+// ClientName returns the WithName label of the client making the request
+// that ctx belongs to, or "" if it has none. It is available to hooks that
+// receive the request context, such as Tracer and WithHeaderFunc.
+func ClientName(ctx context.Context) string {
+	name, _ := ctx.Value(clientNameKey{}).(string)
+	return name
+}
+
+// parseRewrittenQuery accepts rewritten_query either as a plain string or as
+// an object carrying the query under "text" (e.g. {"text": "...", "lang": "en"}).
+func parseRewrittenQuery(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case map[string]interface{}:
+		text, ok := v["text"].(string)
+		return text, ok
+	default:
+		return "", false
+	}
+}
+
+// buildMemorizePayload builds the payload for a Memorize request.
+// This provides unified payload construction logic to simplify the Memorize method.
+// It handles default values for user_name and agent_name, and conditionally includes
+// conversation, conversation_text, session_date and language fields.
+func buildMemorizePayload(req *MemorizeRequest) map[string]interface{} {
+	req = req.withDefaults(newCanonicalConfig())
+	payload := map[string]interface{}{
+		"user_id":    req.UserID,
+		"agent_id":   req.AgentID,
+		"user_name":  req.UserName,
+		"agent_name": req.AgentName,
+	}
+
+	if len(req.Conversation) > 0 {
+		payload["conversation"] = req.Conversation
+	} else if req.ConversationText != nil {
+		payload["conversation_text"] = *req.ConversationText
+	}
+
+	if req.SessionDate != nil {
+		payload["session_date"] = *req.SessionDate
+	}
+
+	if req.Mode != "" {
+		payload["mode"] = string(req.Mode)
+	}
+
+	if req.Language != nil {
+		payload["language"] = *req.Language
+	}
+
+	return payload
+}
+
+// buildRetrievePayload builds the payload for a Retrieve request.
+// TypeWeights, CategoriesOnly and IncludeSources are only included when provided, leaving simple
+// queries unaffected.
+func buildRetrievePayload(req *RetrieveRequest) map[string]interface{} {
+	payload := map[string]interface{}{
+		"user_id":  req.UserID,
+		"agent_id": req.AgentID,
+		"query":    req.Query,
+	}
+
+	if len(req.TypeWeights) > 0 {
+		payload["type_weights"] = req.TypeWeights
+	}
+
+	if req.CategoriesOnly != nil {
+		payload["categories_only"] = *req.CategoriesOnly
+	}
+
+	if req.IncludeSources != nil {
+		payload["include_sources"] = *req.IncludeSources
+	}
+
+	if req.Offset != nil {
+		payload["offset"] = *req.Offset
+	}
+
+	if req.Limit != nil {
+		payload["limit"] = *req.Limit
+	}
+
+	if req.RecencyBoost != nil {
+		payload["recency_boost"] = *req.RecencyBoost
+	}
+
+	return payload
+}
+
+// buildListCategoriesPayload builds the payload for a ListCategories request.
+// agent_id is omitted when AgentID is nil, requesting categories across all
+// agents of the user.
+func buildListCategoriesPayload(req *ListCategoriesRequest) map[string]interface{} {
+	payload := map[string]interface{}{
+		"user_id": req.UserID,
+	}
+
+	if req.AgentID != nil {
+		payload["agent_id"] = *req.AgentID
+	}
+
+	return payload
+}
+
+// callOptions holds per-call settings that are not part of the request body.
+type callOptions struct {
+	// operation is the name of the public method making the call, used to
+	// look up per-operation timeouts.
+	operation string
+	// nonIdempotent marks calls that may create duplicates if replayed.
+	nonIdempotent bool
+	// headers are extra headers sent with every attempt of the call.
+	headers map[string]string
+	// responseHeader, if set, receives the headers of a successful response.
+	responseHeader *http.Header
+}
+
+// request makes an HTTP request to the API with automatic retry logic and
+// parses the successful response body into a JSON object, unwrapping the
+// configured response envelope.
+func (c *Client) request(ctx context.Context, method, path string, body interface{}, params map[string]string, call callOptions) (map[string]interface{}, error) {
+	respBody, _, err := c.doRequest(ctx, method, path, body, params, call)
+	if err != nil {
+		return nil, err
+	}
+	return c.unwrapEnvelope(parseResponseBody(c.codec, respBody)), nil
+}
+
+// unwrapEnvelope returns the object stored under the configured envelope key.
+// Responses without an object under that key are returned unchanged.
+func (c *Client) unwrapEnvelope(response map[string]interface{}) map[string]interface{} {
+	if c.responseEnvelope == "" {
+		return response
+	}
+	if inner, ok := response[c.responseEnvelope].(map[string]interface{}); ok {
+		return inner
+	}
+	return response
+}
+
+// doRequest makes an HTTP request to the API with automatic retry logic and
+// reports the outcome to the configured Metrics and Tracer. It returns the raw response
+// body and the status code of the last response received (0 if none).
+func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}, params map[string]string, call callOptions) ([]byte, int, error) {
+	if err := c.waitForStartup(ctx); err != nil {
+		return nil, 0, err
+	}
+	release, err := c.acquireRequestSlot(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer release()
+
+	if c.name != "" {
+		ctx = context.WithValue(ctx, clientNameKey{}, c.name)
+	}
+
+	var finish func(statusCode int, err error)
+	if c.tracer != nil {
+		ctx, finish = c.tracer.StartOperation(ctx, call.operation)
+	}
+
+	start := time.Now()
+	respBody, statusCode, err := c.sendWithRetry(ctx, method, path, body, params, call)
+	c.observeRequest(call.operation, statusCode, time.Since(start), err)
+	if finish != nil {
+		finish(statusCode, err)
+	}
+	return respBody, statusCode, err
+}
+
+// acquireRequestSlot blocks until fewer than maxConcurrentRequests calls are
+// in flight and returns a function releasing the slot. It returns the context
+// error if ctx is done first.
+func (c *Client) acquireRequestSlot(ctx context.Context) (func(), error) {
+	if c.requestSlots == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.requestSlots <- struct{}{}:
+		return func() { <-c.requestSlots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// waitForStartup delays requests until the startup jitter chosen on the first
+// request has elapsed. It returns early with the context error if ctx is done.
+// The delay is measured on the monotonic wall clock rather than c.now, so a
+// fixed WithTimeFunc cannot stall requests indefinitely.
+func (c *Client) waitForStartup(ctx context.Context) error {
+	if c.startupJitter <= 0 {
+		return nil
+	}
+	c.startupOnce.Do(func() {
+		c.startupAt = time.Now().Add(time.Duration(rand.Int63n(int64(c.startupJitter) + 1)))
+	})
+
+	wait := time.Until(c.startupAt)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sendWithRetry makes an HTTP request to the API with automatic retry logic.
+// It handles request construction, header setting, query parameters, rate limiting,
+// and error handling. The method automatically retries on transient errors
+// based on the configured retry policy.
+func (c *Client) sendWithRetry(ctx context.Context, method, path string, body interface{}, params map[string]string, call callOptions) ([]byte, int, error) {
+	// Marshal the request body once so its size can be checked before sending
+	var jsonData []byte
+	if body != nil {
+		var err error
+		jsonData, err = c.codec.Marshal(body)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		if c.maxRequestBytes > 0 && int64(len(jsonData)) > c.maxRequestBytes {
+			return nil, 0, NewRequestTooLargeError(int64(len(jsonData)), c.maxRequestBytes)
+		}
+	}
+
+	refreshed := false
+	for attempt := 0; ; attempt++ {
+		// Prepare request body
+		var bodyReader io.Reader
+		if jsonData != nil {
+			bodyReader = bytes.NewReader(jsonData)
+		}
+
+		// Create request
+		attemptCtx, cancelAttempt := c.attemptContext(ctx, call)
+		url := c.baseURL + path
+		req, err := http.NewRequestWithContext(attemptCtx, method, url, bodyReader)
+		if err != nil {
+			cancelAttempt()
+			return nil, 0, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		// Set headers
+		for key, value := range c.defaultHeaders() {
+			req.Header.Set(key, value)
+		}
+		if c.requestIDExtractor != nil {
+			if requestID := c.requestIDExtractor(ctx); requestID != "" {
+				req.Header.Set("X-Correlation-Id", requestID)
+			}
+		}
+		if c.headerFunc != nil {
+			for key, value := range c.headerFunc(ctx, method, path) {
+				if http.CanonicalHeaderKey(key) == "Authorization" && !c.allowAuthOverride {
+					continue
+				}
+				req.Header.Set(key, value)
+			}
+		}
+		for key, value := range call.headers {
+			req.Header.Set(key, value)
+		}
+
+		// Set query parameters
+		if len(params) > 0 {
+			q := req.URL.Query()
+			for key, value := range params {
+				q.Add(key, value)
+			}
+			req.URL.RawQuery = q.Encode()
+		}
+		c.lastRequestURLMu.Lock()
+		c.lastRequestURL = req.URL.String()
+		c.lastRequestURLMu.Unlock()
+
+		// Make request
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			cancelAttempt()
+			// Check if we should retry
+			if c.shouldRetryTransportError(ctx, attempt, err, call) {
+				c.observeRetry(call.operation, transportRetryCause(err), 0, err)
+				time.Sleep(c.retryPolicy.GetBackoff(attempt))
+				continue
+			}
+			return nil, 0, fmt.Errorf("request failed after %d attempts: %w", attempt+1, err)
+		}
+
+		// Read response body, closing it before any retry so connections are not held
+		respBody, err := readResponseBody(resp, c.maxResponseBytes)
+		resp.Body.Close()
+		cancelAttempt()
+		if err != nil {
+			return nil, resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+		}
+
+		// Success, unless the body reports a transient failure
+		if resp.StatusCode < 400 {
+			if err := unexpectedContentType(resp, path, respBody); err != nil {
+				return nil, resp.StatusCode, err
+			}
+			if c.bodyRetryPredicate != nil && c.bodyRetryPredicate(parseResponseBody(c.codec, respBody)) &&
+				c.retryPolicy.ShouldRetry(attempt, 0, ErrTransientResponse) {
+				c.observeRetry(call.operation, RetryCauseTransientBody, resp.StatusCode, ErrTransientResponse)
+				time.Sleep(c.retryPolicy.GetBackoff(attempt))
+				continue
+			}
+			if call.responseHeader != nil {
+				*call.responseHeader = resp.Header
+			}
+			return respBody, resp.StatusCode, nil
+		}
+
+		// Parse error response
+		result := parseResponseBody(c.codec, respBody)
+
+		// Handle rate limiting (429, or 403 with Retry-After if configured)
+		retryAfter := resp.Header.Get("Retry-After")
+		throttled403 := c.treat403AsRateLimit && resp.StatusCode == http.StatusForbidden && retryAfter != ""
+		if resp.StatusCode == http.StatusTooManyRequests || throttled403 {
+			var waitTime time.Duration
+			if retryAfter != "" {
+				waitTime = c.parseRetryAfter(retryAfter)
+			} else {
+				waitTime = c.retryPolicy.GetBackoff(attempt)
+			}
+			if c.onRateLimit != nil {
+				c.onRateLimit(waitTime)
+			}
+
+			// Throttling 403s follow the retry policy for 429
+			if c.retryPolicy.ShouldRetry(attempt, http.StatusTooManyRequests, nil) {
+				c.observeRetry(call.operation, RetryCauseStatus429, resp.StatusCode, nil)
+				time.Sleep(waitTime)
+				continue
+			}
+
+			retryAfterFloat := float64(waitTime) / float64(time.Second)
+			statusCode := resp.StatusCode
+			rateLimitErr := NewRateLimitError(responseMessage(result, "rate limit exceeded"), &retryAfterFloat, &statusCode, result)
+			rateLimitErr.normalize("RateLimitError", path)
+			return nil, resp.StatusCode, rateLimitErr
+		}
+
+		// Handle server errors (5xx) - retry
+		if resp.StatusCode >= 500 {
+			if c.retryPolicy.ShouldRetry(attempt, resp.StatusCode, nil) {
+				c.observeRetry(call.operation, RetryCauseStatus5xx, resp.StatusCode, nil)
+				time.Sleep(c.retryPolicy.GetBackoff(attempt))
+				continue
+			}
+			if err := unexpectedContentType(resp, path, respBody); err != nil {
+				return nil, resp.StatusCode, err
+			}
+			statusCode := resp.StatusCode
+			// Fall back to the raw response body for debugging
+			fallback := "server error"
+			if body := strings.TrimSpace(string(respBody)); body != "" {
+				fallback = "server error, response: " + body
+			}
+			clientErr := NewClientError(responseMessage(result, fallback), &statusCode, result)
+			clientErr.normalize("ClientError", path)
+			return nil, resp.StatusCode, clientErr
+		}
+
+		// Handle expired credentials (401) - refresh the token and retry once
+		if resp.StatusCode == http.StatusUnauthorized && c.tokenRefresh != nil && !refreshed {
+			refreshed = true
+			if c.refreshAPIKey(ctx) {
+				c.observeRetry(call.operation, RetryCauseAuthRefresh, resp.StatusCode, nil)
+				continue
+			}
+		}
+
+		// Retry client errors whose message marks them as transient
+		if c.isRetryableErrorMessage(result, respBody) &&
+			c.retryPolicy.ShouldRetry(attempt, resp.StatusCode, ErrTransientResponse) {
+			c.observeRetry(call.operation, RetryCauseErrorMessage, resp.StatusCode, ErrTransientResponse)
+			time.Sleep(c.retryPolicy.GetBackoff(attempt))
+			continue
+		}
+
+		// Handle client errors (4xx) - don't retry
+		if err := unexpectedContentType(resp, path, respBody); err != nil {
+			return nil, resp.StatusCode, err
+		}
+		return nil, resp.StatusCode, c.raiseForStatus(resp.StatusCode, path, result)
+	}
+}
+
+// contentTypeSnippetRunes is the maximum length of the body snippet in an
+// UnexpectedContentTypeError.
+const contentTypeSnippetRunes = 200
+
+// unexpectedContentType returns an UnexpectedContentTypeError if the response
+// is an HTML page rather than JSON. Plain-text bodies are left to the regular
+// error handling, which includes them in the message, and bodies that are
+// valid JSON despite their Content-Type are accepted.
+func unexpectedContentType(resp *http.Response, path string, body []byte) error {
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || (mediaType != "text/html" && mediaType != "application/xhtml+xml") {
+		return nil
+	}
+	if len(bytes.TrimSpace(body)) == 0 || json.Valid(body) {
+		return nil
+	}
+	snippet := strings.Join(strings.Fields(string(body)), " ")
+	return NewUnexpectedContentTypeError(contentType, resp.StatusCode, path, truncateRunes(&snippet, contentTypeSnippetRunes))
+}
+
+// readResponseBody reads the whole response body. It does not depend on
+// Content-Length, so chunked responses are read to the end. The transport
+// decompresses gzip transparently when it requested it; bodies it left
+// compressed, e.g. because Accept-Encoding was set through WithHeaderFunc,
+// are decompressed here. Decoded bodies larger than maxBytes fail with a
+// ResponseTooLargeError; a maxBytes of 0 disables the limit.
+func readResponseBody(resp *http.Response, maxBytes int64) ([]byte, error) {
+	if resp.Uncompressed || !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return readLimited(resp.Body, maxBytes)
+	}
+	reader, err := gzip.NewReader(resp.Body)
+	if err == io.EOF {
+		// An empty body, e.g. for 204, carries no gzip header
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress gzip response: %w", err)
+	}
+	defer reader.Close()
+	return readLimited(reader, maxBytes)
+}
+
+// readLimited reads r to the end, failing once more than maxBytes are read.
+func readLimited(r io.Reader, maxBytes int64) ([]byte, error) {
+	if maxBytes <= 0 {
+		return io.ReadAll(r)
+	}
+	body, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxBytes {
+		return nil, NewResponseTooLargeError(maxBytes)
+	}
+	return body, nil
+}
+
+// isRetryableErrorMessage reports whether an error response message contains
+// one of the substrings configured with WithRetryableErrorMessages. The
+// "message" field is matched when present, otherwise the raw body.
+func (c *Client) isRetryableErrorMessage(response map[string]interface{}, body []byte) bool {
+	if len(c.retryableErrorMessages) == 0 {
+		return false
+	}
+	message, ok := response["message"].(string)
+	if !ok {
+		message = string(body)
+	}
+	message = strings.ToLower(message)
+	for _, substr := range c.retryableErrorMessages {
+		if strings.Contains(message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header value, which may be either a
+// number of seconds or an HTTP date. Dates are resolved against the client's
+// clock. Unparseable values and dates in the past yield zero.
+func (c *Client) parseRetryAfter(value string) time.Duration {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(c.now()); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// shouldRetryTransportError reports whether a transport-level error should be retried.
+// Caller context cancellation and deadlines are never retried. Non-idempotent
+// calls are not retried after errors that may have reached the server unless
+// retryNonIdempotent is enabled. Transport timeouts are retried only when
+// retryOnTimeout is enabled. All other errors defer to the retry policy.
+func (c *Client) shouldRetryTransportError(ctx context.Context, attempt int, err error, call callOptions) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if call.nonIdempotent && !c.retryNonIdempotent && isAmbiguousTransportError(err) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() && !c.retryOnTimeout {
+		return false
+	}
+	return c.retryPolicy.ShouldRetry(attempt, 0, err)
+}
+
+// attemptContext returns the context for a single request attempt. With
+// per-operation timeouts configured, each attempt is bounded by the timeout
+// of call.operation, or the client timeout for unlisted operations.
+func (c *Client) attemptContext(ctx context.Context, call callOptions) (context.Context, context.CancelFunc) {
+	if len(c.methodTimeouts) == 0 {
+		return ctx, func() {}
+	}
+	timeout, ok := c.methodTimeouts[call.operation]
+	if !ok {
+		timeout = c.timeout
+	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// isAmbiguousTransportError reports whether a transport error may have occurred
+// after the request reached the server. Only failures to connect are known to
+// be safe to replay.
+func isAmbiguousTransportError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return false
+	}
+	return true
+}
+
+// raiseForStatus raises an appropriate error for HTTP error status codes.
+// It maps HTTP status codes to specific error types: 401 to AuthenticationError,
+// 404 to NotFoundError, 422 to ValidationError, and others to generic ClientError.
+// Messages are normalized with ClientError.normalize.
+func (c *Client) raiseForStatus(statusCode int, path string, response map[string]interface{}) error {
+	status := &statusCode
+
+	switch statusCode {
+	case http.StatusUnauthorized:
+		err := NewAuthenticationError(status, response)
+		err.normalize("AuthenticationError", path)
+		return err
+	case http.StatusNotFound:
+		err := NewNotFoundError(path, status, response)
+		// The normalized message carries the path already
+		err.Message = responseMessage(response, "Resource not found")
+		err.normalize("NotFoundError", path)
+		return err
+	case http.StatusUnprocessableEntity:
+		err := NewValidationError(status, response)
+		if c.isMemorizePath(path) {
+			err.Reason = parseValidationReason(response)
+		}
+		err.normalize("ValidationError", path)
+		return err
+	default:
+		err := NewClientError(responseMessage(response, http.StatusText(statusCode)), status, response)
+		err.normalize("ClientError", path)
+		return err
+	}
+}
+
+// isMemorizePath reports whether path is a Memorize or MemorizeBatch endpoint,
+// whose 422 responses are classified into a ValidationReason.
+func (c *Client) isMemorizePath(path string) bool {
+	return path == c.apiPath("/memory/memorize") || path == c.apiPath("/memory/memorize/batch")
+}
+
+// responseMessage returns the "message" field of an error response, or
+// fallback if it is missing or empty.
+func responseMessage(response map[string]interface{}, fallback string) string {
+	if msg, ok := response["message"].(string); ok && msg != "" {
+		return msg
+	}
+	return fallback
+}
+
+// applyRoleAliases returns messages with the WithRoleAliases aliases applied.
+// The input slice is returned as-is when no role is aliased, and copied
+// otherwise.
+func (c *Client) applyRoleAliases(messages []ConversationMessage) []ConversationMessage {
+	var aliased []ConversationMessage
+	for i, msg := range messages {
+		role, ok := c.roleAliases[msg.Role]
+		if !ok {
+			continue
+		}
+		if aliased == nil {
+			aliased = append([]ConversationMessage(nil), messages...)
+		}
+		aliased[i].Role = role
+	}
+	if aliased == nil {
+		return messages
+	}
+	return aliased
+}
+
+// normalizeID applies the WithNormalizeIDs normalizer, if any, to id.
+func (c *Client) normalizeID(id string) string {
+	if c.idNormalizer == nil {
+		return id
+	}
+	return c.idNormalizer(id)
+}
+
+// normalizePayloadIDs normalizes the user_id and agent_id of a request
+// payload in place.
+func (c *Client) normalizePayloadIDs(payload map[string]interface{}) {
+	for _, key := range []string{"user_id", "agent_id"} {
+		if id, ok := payload[key].(string); ok {
+			payload[key] = c.normalizeID(id)
+		}
+	}
+}
+
+// validate runs req.Validate unless WithSkipValidation is set.
+func (c *Client) validate(req Validator) error {
+	if c.skipValidation {
+		return nil
+	}
+	if v, ok := req.(timeFormatValidator); ok {
+		return invalidRequest(v.validateWithTimeFormat(c.timeFormat))
+	}
+	return invalidRequest(req.Validate())
+}
+
+// timeFormatValidator is implemented by requests with timestamps, so that
+// validation also accepts the layout set with WithTimeFormat.
+type timeFormatValidator interface {
+	validateWithTimeFormat(layout string) error
+}
+
+// checkedMemorizePayload validates a Memorize request, applying the checks
+// enabled by client options, and builds its payload.
+func (c *Client) checkedMemorizePayload(req *MemorizeRequest) (map[string]interface{}, error) {
+	if len(c.roleAliases) > 0 {
+		aliased := *req
+		aliased.Conversation = c.applyRoleAliases(req.Conversation)
+		req = &aliased
+	}
+	if err := c.validate(req); err != nil {
+		return nil, err
+	}
+	if c.rejectDuplicateMessages {
+		if err := req.validateNoConsecutiveDuplicates(); err != nil {
+			return nil, invalidRequest(err)
+		}
+	}
+	if c.requireChronologicalMessages {
+		if err := req.validateChronological(c.timeFormat); err != nil {
+			return nil, invalidRequest(err)
+		}
+	}
+	if c.maxConversationMessages > 0 {
+		if err := req.validateMaxMessages(c.maxConversationMessages); err != nil {
+			return nil, invalidRequest(err)
+		}
+	}
+	if c.rejectFutureSessionDate {
+		if err := req.validateSessionDateNotAfter(c.now().Add(c.futureSessionDateTolerance), c.timeFormat); err != nil {
+			return nil, invalidRequest(err)
+		}
+	}
+
+	payload := buildMemorizePayload(req)
+	c.normalizePayloadIDs(payload)
+	if c.validatePayloadSchema {
+		if err := validatePayload(c.codec, "Memorize", memorizePayloadSchema, payload); err != nil {
+			return nil, err
+		}
+	}
+	return payload, nil
+}
+
+// PayloadSize returns the size in bytes of the body Memorize would send for
+// req, encoded with the configured Codec and with client options such as
+// WithRoleAliases applied. This is the size checked by WithMaxRequestBytes.
+// Requests that fail validation return the validation error.
+func (c *Client) PayloadSize(req *MemorizeRequest) (int, error) {
+	if req == nil {
+		return 0, invalidRequest(errors.New("PayloadSize: request is required"))
+	}
+	payload, err := c.checkedMemorizePayload(req)
+	if err != nil {
+		return 0, err
+	}
+	return encodedSize(c.codec, payload)
+}
+
+// Memorize memorizes a conversation and extracts structured memory.
+func (c *Client) Memorize(ctx context.Context, req *MemorizeRequest) (*MemorizeResult, error) {
+	if req == nil {
+		return nil, invalidRequest(errors.New("Memorize: request is required"))
+	}
+
+	payload, err := c.checkedMemorizePayload(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make request
+	// Without an idempotency key a replayed request may create a duplicate task
+	call := callOptions{operation: "Memorize", nonIdempotent: true}
+	if req.IdempotencyKey != nil && *req.IdempotencyKey != "" {
+		call = callOptions{operation: "Memorize", headers: map[string]string{"Idempotency-Key": *req.IdempotencyKey}}
+	}
+	response, err := c.request(ctx, "POST", c.apiPath("/memory/memorize"), payload, nil, call)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := parseMemorizeResult(c.codec, response)
+	if err != nil {
+		return nil, err
+	}
+	c.logWarnings("Memorize", result.Warnings)
+	// A success without a task or extracted memory leaves nothing to act on
+	if result.IsEmpty() {
+		return nil, NewResponseValidationError("Memorize", "response has neither task_id nor status", response)
+	}
+	return result, nil
+}
+
+// ReprocessResource re-runs memory extraction on an already stored resource
+// and returns the new memorization task.
+func (c *Client) ReprocessResource(ctx context.Context, req *ReprocessRequest) (*MemorizeResult, error) {
+	if req == nil {
+		return nil, invalidRequest(errors.New("ReprocessResource: request is required"))
+	}
+
+	if err := c.validate(req); err != nil {
+		return nil, err
+	}
+
+	// Build request payload
+	payload := map[string]interface{}{
+		"user_id":  req.UserID,
+		"agent_id": req.AgentID,
+	}
+	if req.ResourceID != "" {
+		payload["resource_id"] = req.ResourceID
+	}
+	if req.ResourceURL != "" {
+		payload["resource_url"] = req.ResourceURL
+	}
+	c.normalizePayloadIDs(payload)
+
+	// Make request
+	response, err := c.request(ctx, "POST", c.apiPath("/memory/reprocess"), payload, nil, callOptions{operation: "ReprocessResource"})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseMemorizeResult(c.codec, response)
+}
+
+// GetTaskStatus gets the status of a memorization task.
+func (c *Client) GetTaskStatus(ctx context.Context, taskID string) (*TaskStatus, error) {
+	if taskID == "" {
+		return nil, invalidRequest(errors.New("taskID is required"))
+	}
+	if err := validateID("GetTaskStatus", "taskID", taskID); err != nil {
+		return nil, err
+	}
+
+	// Terminal statuses never change, so serve them from the memo
+	if c.taskStatusMemo {
+		if status, ok := c.terminalTasks.get(taskID); ok {
+			return status, nil
+		}
+	}
+
+	path := c.apiPath(fmt.Sprintf("/memory/memorize/status/%s", url.PathEscape(taskID)))
+	if c.etags != nil {
+		return c.getTaskStatusConditional(ctx, taskID, path)
+	}
+	response, err := c.request(ctx, "GET", path, nil, nil, callOptions{operation: "GetTaskStatus"})
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := parseTaskStatus(c.codec, response)
+	if err != nil {
+		return nil, err
+	}
+
+	c.memoizeTerminalStatus(taskID, status)
+
+	return status, nil
+}
+
+// getTaskStatusConditional fetches a task status with If-None-Match set to
+// the task's cached ETag, returning the cached status on 304 Not Modified.
+func (c *Client) getTaskStatusConditional(ctx context.Context, taskID, path string) (*TaskStatus, error) {
+	var header http.Header
+	call := callOptions{operation: "GetTaskStatus", responseHeader: &header}
+	cached, hasCached := c.etags.get(taskID)
+	if hasCached {
+		call.headers = map[string]string{"If-None-Match": cached.etag}
+	}
+
+	respBody, statusCode, err := c.doRequest(ctx, "GET", path, nil, nil, call)
+	if err != nil {
+		return nil, err
+	}
+	if statusCode == http.StatusNotModified && hasCached {
+		return cached.status.clone(), nil
+	}
+
+	status, err := parseTaskStatus(c.codec, c.unwrapEnvelope(parseResponseBody(c.codec, respBody)))
+	if err != nil {
+		return nil, err
+	}
+	if etag := header.Get("ETag"); etag != "" {
+		c.etags.put(taskID, etag, status)
+	}
+
+	c.memoizeTerminalStatus(taskID, status)
+
+	return status, nil
+}
+
+// ReassignMemories moves a user's memories from one agent to another and
+// returns the number of memory items moved.
+func (c *Client) ReassignMemories(ctx context.Context, req *ReassignRequest) (*ReassignResult, error) {
+	if req == nil {
+		return nil, invalidRequest(errors.New("ReassignMemories: request is required"))
+	}
+
+	if err := c.validate(req); err != nil {
+		return nil, err
+	}
+
+	// Build request payload
+	payload := map[string]interface{}{
+		"user_id":       c.normalizeID(req.UserID),
+		"from_agent_id": c.normalizeID(req.FromAgentID),
+		"to_agent_id":   c.normalizeID(req.ToAgentID),
+	}
+
+	// Make request
+	response, err := c.request(ctx, "POST", c.apiPath("/memory/reassign"), payload, nil, callOptions{operation: "ReassignMemories"})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := parseJSONObject[ReassignResult](c.codec, response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse reassign result: %w", err)
+	}
+	return result, nil
+}
+
+// TryGetTaskStatus is like GetTaskStatus but reports a task that does not
+// exist as (nil, false, nil) instead of a NotFoundError. A found task is
+// returned as (status, true, nil); the error is reserved for other failures.
+func (c *Client) TryGetTaskStatus(ctx context.Context, taskID string) (*TaskStatus, bool, error) {
+	status, err := c.GetTaskStatus(ctx, taskID)
+	if err != nil {
+		var notFound *NotFoundError
+		if errors.As(err, &notFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return status, true, nil
+}
+
+// PurgeFailedTasks deletes the FAILED memorization task records of a user
+// and agent and returns the number of records removed.
+func (c *Client) PurgeFailedTasks(ctx context.Context, userID, agentID string) (int, error) {
+	if userID == "" {
+		return 0, invalidRequest(errors.New("PurgeFailedTasks: userID is required"))
+	}
+	if agentID == "" {
+		return 0, invalidRequest(errors.New("PurgeFailedTasks: agentID is required"))
+	}
+	if err := validateID("PurgeFailedTasks", "userID", userID); err != nil {
+		return 0, err
+	}
+	if err := validateID("PurgeFailedTasks", "agentID", agentID); err != nil {
+		return 0, err
+	}
+
+	payload := map[string]interface{}{
+		"user_id":  userID,
+		"agent_id": agentID,
+		"status":   string(TaskStatusFailed),
+	}
+	c.normalizePayloadIDs(payload)
+	response, err := c.request(ctx, "POST", c.apiPath("/memory/memorize/tasks/purge"), payload, nil, callOptions{operation: "PurgeFailedTasks"})
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, ok := response["deleted_count"].(float64)
+	if !ok {
+		return 0, NewResponseValidationError("PurgeFailedTasks", "response has no deleted_count", response)
+	}
+	return int(deleted), nil
+}
+
+// CancelTask cancels a pending or processing memorization task and returns its
+// resulting status (typically CANCELLED or FAILED). Cancelling a task that has
+// already finished is not an error: its current terminal status is returned.
+func (c *Client) CancelTask(ctx context.Context, taskID string) (*TaskStatus, error) {
+	if taskID == "" {
+		return nil, invalidRequest(errors.New("taskID is required"))
+	}
+	if err := validateID("CancelTask", "taskID", taskID); err != nil {
+		return nil, err
+	}
+
+	// A memoized terminal task is already finished
+	if c.taskStatusMemo {
+		if status, ok := c.terminalTasks.get(taskID); ok {
+			return status, nil
+		}
+	}
+
+	path := c.apiPath(fmt.Sprintf("/memory/memorize/status/%s/cancel", url.PathEscape(taskID)))
+	response, err := c.request(ctx, "POST", path, nil, nil, callOptions{operation: "CancelTask"})
+	if err != nil {
+		// The server rejects cancelling a finished task with 409 Conflict
+		var clientErr *ClientError
+		if errors.As(err, &clientErr) && clientErr.StatusCode != nil && *clientErr.StatusCode == http.StatusConflict {
+			if status, statusErr := c.GetTaskStatus(ctx, taskID); statusErr == nil && status.Status.IsTerminal() {
+				return status, nil
+			}
+		}
+		return nil, err
+	}
+
+	status, err := parseTaskStatus(c.codec, response)
+	if err != nil {
+		return nil, err
+	}
+
+	c.memoizeTerminalStatus(taskID, status)
+
+	return status, nil
+}
+
+// memoizeTerminalStatus stores a copy of a terminal task status when the
+// task status memo is enabled.
+func (c *Client) memoizeTerminalStatus(taskID string, status *TaskStatus) {
+	if c.taskStatusMemo && status.Status.IsTerminal() {
+		c.terminalTasks.put(taskID, status)
+	}
+}
+
+// ListCategories lists all memory categories.
+func (c *Client) ListCategories(ctx context.Context, req *ListCategoriesRequest) ([]*MemoryCategory, error) {
+	if req == nil {
+		return nil, invalidRequest(errors.New("ListCategories: request is required"))
+	}
+
+	if err := c.validate(req); err != nil {
+		return nil, err
+	}
+
+	// Build request payload
+	payload := buildListCategoriesPayload(req)
+	c.normalizePayloadIDs(payload)
+
+	// Make request
+	response, err := c.request(ctx, "POST", c.apiPath("/memory/categories"), payload, nil, callOptions{operation: "ListCategories"})
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := parseCategories(c.codec, response)
+	if err != nil {
+		return nil, err
+	}
+
+	annotateCategoryAgent(categories, req.AgentID)
+
+	return categories, nil
+}
+
+// annotateCategoryAgent sets AgentID on categories of an agent-scoped listing
+// that the server returned without one.
+func annotateCategoryAgent(categories []*MemoryCategory, agentID *string) {
+	if agentID == nil {
+		return
+	}
+	for _, category := range categories {
+		if category != nil && category.AgentID == nil {
+			id := *agentID
+			category.AgentID = &id
+		}
+	}
+}
+
+// ListMemoryTypes lists the distinct memory types stored for a user and
+// agent, sorted alphabetically.
+func (c *Client) ListMemoryTypes(ctx context.Context, userID, agentID string) ([]string, error) {
+	if userID == "" {
+		return nil, invalidRequest(errors.New("ListMemoryTypes: userID is required"))
+	}
+	if agentID == "" {
+		return nil, invalidRequest(errors.New("ListMemoryTypes: agentID is required"))
+	}
+	if err := validateID("ListMemoryTypes", "userID", userID); err != nil {
+		return nil, err
+	}
+	if err := validateID("ListMemoryTypes", "agentID", agentID); err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{
+		"user_id":  userID,
+		"agent_id": agentID,
+	}
+	c.normalizePayloadIDs(payload)
+	response, err := c.request(ctx, "POST", c.apiPath("/memory/types"), payload, nil, callOptions{operation: "ListMemoryTypes"})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseMemoryTypes(c.codec, response)
+}
+
+// Retrieve retrieves relevant memories based on a query.
+func (c *Client) Retrieve(ctx context.Context, req *RetrieveRequest) (*RetrieveResult, error) {
+	if req == nil {
+		return nil, invalidRequest(errors.New("Retrieve: request is required"))
+	}
+
+	result, err := c.retrieve(ctx, req)
+	if err != nil || c.retrieveFallbackQuery == nil || len(result.Items) > 0 ||
+		(req.CategoriesOnly != nil && *req.CategoriesOnly) {
+		return result, err
+	}
+
+	// Nothing matched; re-run with the broader fallback query
+	fallback := *req
+	fallback.Query = c.retrieveFallbackQuery
+	result, err = c.retrieve(ctx, &fallback)
+	if err != nil {
+		return nil, err
+	}
+	result.Fallback = true
 	return result, nil
 }
+
+// retrieve runs a single Retrieve request, following pages if enabled.
+func (c *Client) retrieve(ctx context.Context, req *RetrieveRequest) (*RetrieveResult, error) {
+	if messages, ok := req.Query.([]ConversationMessage); ok && len(c.roleAliases) > 0 {
+		aliased := *req
+		aliased.Query = c.applyRoleAliases(messages)
+		req = &aliased
+	}
+	if err := c.validate(req); err != nil {
+		return nil, err
+	}
+
+	// Build request payload
+	payload := buildRetrievePayload(req)
+	c.normalizePayloadIDs(payload)
+	if c.queryAsConversation {
+		if query, ok := req.Query.(string); ok {
+			payload["query"] = []ConversationMessage{{Role: "user", Content: query}}
+		}
+	}
+	if c.validatePayloadSchema {
+		if err := validatePayload(c.codec, "Retrieve", retrievePayloadSchema, payload); err != nil {
+			return nil, err
+		}
+	}
+
+	// Make request
+	response, err := c.request(ctx, "POST", c.apiPath("/memory/retrieve"), payload, req.ExtraParams, callOptions{operation: "Retrieve"})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.parseRetrieveResponse(response)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.autoPaginateMaxItems > 0 {
+		if err := c.retrieveRemainingPages(ctx, payload, req.ExtraParams, response, result); err != nil {
+			return nil, err
+		}
+	}
+
+	c.logWarnings("Retrieve", result.Warnings)
+
+	// Drop anything beyond categories in case the server ignored the flag
+	if req.CategoriesOnly != nil && *req.CategoriesOnly {
+		result.Items = nil
+		result.Resources = nil
+	}
+
+	// Always return empty rather than nil slices for a stable JSON shape
+	return result.Normalize(false), nil
+}
+
+// RetrieveSimilar retrieves memories related to a seed memory, given either
+// as the ID of a stored item or as content, instead of a text query.
+func (c *Client) RetrieveSimilar(ctx context.Context, req *RetrieveSimilarRequest) (*RetrieveResult, error) {
+	if req == nil {
+		return nil, invalidRequest(errors.New("RetrieveSimilar: request is required"))
+	}
+
+	if err := c.validate(req); err != nil {
+		return nil, err
+	}
+
+	// Build request payload
+	payload := map[string]interface{}{
+		"user_id":  c.normalizeID(req.UserID),
+		"agent_id": c.normalizeID(req.AgentID),
+	}
+	if req.ItemID != "" {
+		payload["item_id"] = req.ItemID
+	}
+	if req.Content != "" {
+		payload["content"] = req.Content
+	}
+
+	// Make request
+	response, err := c.request(ctx, "POST", c.apiPath("/memory/retrieve/similar"), payload, nil, callOptions{operation: "RetrieveSimilar"})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.parseRetrieveResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	return result.Normalize(false), nil
+}
+
+// RecentMemories returns the most recently created memory items of a user and
+// agent, newest first, without a query. Items are ordered by CreatedAt on the
+// client as well, with items lacking a parseable CreatedAt last, and cut to
+// req.Limit if the server returns more.
+func (c *Client) RecentMemories(ctx context.Context, req *RecentMemoriesRequest) ([]*MemoryItem, error) {
+	if req == nil {
+		return nil, invalidRequest(errors.New("RecentMemories: request is required"))
+	}
+
+	if err := c.validate(req); err != nil {
+		return nil, err
+	}
+
+	// Build request payload
+	payload := map[string]interface{}{
+		"user_id":  c.normalizeID(req.UserID),
+		"agent_id": c.normalizeID(req.AgentID),
+	}
+	if req.Limit > 0 {
+		payload["limit"] = req.Limit
+	}
+
+	// Make request
+	response, err := c.request(ctx, "POST", c.apiPath("/memory/items/recent"), payload, nil, callOptions{operation: "RecentMemories"})
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse response
+	data, _ := response["items"].([]interface{})
+	items, err := parseJSONArray[MemoryItem](c.codec, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse memory items: %w", err)
+	}
+	c.sortByRecency(items)
+	if req.Limit > 0 && len(items) > req.Limit {
+		items = items[:req.Limit]
+	}
+	return items, nil
+}
+
+// sortByRecency sorts items by CreatedAt, newest first. Items without a
+// parseable CreatedAt keep their relative order after the dated ones.
+func (c *Client) sortByRecency(items []*MemoryItem) {
+	createdAt := func(item *MemoryItem) (time.Time, bool) {
+		if item == nil || item.CreatedAt == nil {
+			return time.Time{}, false
+		}
+		return parseTimestamp(*item.CreatedAt, c.timeFormat)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		ti, okI := createdAt(items[i])
+		tj, okJ := createdAt(items[j])
+		if okI != okJ {
+			return okI
+		}
+		return ti.After(tj)
+	})
+}
+
+// retrieveRemainingPages follows next_cursor from a Retrieve response,
+// appending the items of later pages to result until autoPaginateMaxItems
+// items have been collected or no further page exists. Categories and
+// resources are taken from the first page only.
+func (c *Client) retrieveRemainingPages(ctx context.Context, payload map[string]interface{}, params map[string]string, response map[string]interface{}, result *RetrieveResult) error {
+	seen := make(map[string]bool)
+	for len(result.Items) < c.autoPaginateMaxItems {
+		cursor, _ := response["next_cursor"].(string)
+		// Stop on the last page or if the server repeats a cursor
+		if cursor == "" || seen[cursor] {
+			break
+		}
+		seen[cursor] = true
+
+		payload["cursor"] = cursor
+		var err error
+		response, err = c.request(ctx, "POST", c.apiPath("/memory/retrieve"), payload, params, callOptions{operation: "Retrieve"})
+		if err != nil {
+			return err
+		}
+		page, err := c.parseRetrieveResponse(response)
+		if err != nil {
+			return err
+		}
+		result.Items = append(result.Items, page.Items...)
+		result.DroppedElements += page.DroppedElements
+		result.Warnings = append(result.Warnings, page.Warnings...)
+	}
+
+	if len(result.Items) > c.autoPaginateMaxItems {
+		result.Items = result.Items[:c.autoPaginateMaxItems]
+	}
+	return nil
+}
+
+// GetMemoryItem gets a single memory item by ID.
+// It returns a NotFoundError when the item does not exist, including when a
+// successful response has an empty body or a null item.
+func (c *Client) GetMemoryItem(ctx context.Context, req *GetMemoryItemRequest) (*MemoryItem, error) {
+	if req == nil {
+		return nil, invalidRequest(errors.New("GetMemoryItem: request is required"))
+	}
+
+	if err := c.validate(req); err != nil {
+		return nil, err
+	}
+
+	// Make request
+	path := c.apiPath(fmt.Sprintf("/memory/items/%s", url.PathEscape(req.ID)))
+	params := map[string]string{
+		"user_id": c.normalizeID(req.UserID),
+	}
+	response, err := c.request(ctx, "GET", path, nil, params, callOptions{operation: "GetMemoryItem"})
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse response, which may wrap the item in an "item" field
+	var itemData interface{} = response
+	if wrapped, ok := response["item"]; ok {
+		itemData = wrapped
+	}
+	// An empty body or a null item means the item does not exist
+	if len(response) == 0 || itemData == nil {
+		return nil, NewNotFoundError(path, nil, response)
+	}
+	item, err := parseJSONObject[MemoryItem](c.codec, itemData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse memory item: %w", err)
+	}
+
+	return item, nil
+}
+
+// Ping checks that the API is reachable and the API key is accepted, for use
+// in health and readiness probes. It returns nil on success and the request
+// error otherwise.
+func (c *Client) Ping(ctx context.Context) error {
+	_, _, err := c.doRequest(ctx, "GET", c.apiPath("/health"), nil, nil, callOptions{operation: "Ping"})
+	return err
+}
+
+// APIVersion returns the API version reported by the server, e.g. "v3", so
+// callers can check it supports the version configured with WithAPIVersion.
+func (c *Client) APIVersion(ctx context.Context) (string, error) {
+	response, err := c.request(ctx, "GET", "/api/version", nil, nil, callOptions{operation: "APIVersion"})
+	if err != nil {
+		return "", err
+	}
+	version, ok := response["version"].(string)
+	if !ok || version == "" {
+		return "", NewResponseValidationError("APIVersion", "response has no version", response)
+	}
+	return version, nil
+}
+
+// apiPath returns path under the /api/<version> prefix of the configured API
+// version. path must start with a slash.
+func (c *Client) apiPath(path string) string {
+	return "/api/" + c.apiVersion + path
+}
+
+// LastRequestURL returns the full URL, including query parameters, of the
+// most recent request attempt sent by the client, or "" if none has been sent.
+// With concurrent calls it reflects whichever attempt started last.
+func (c *Client) LastRequestURL() string {
+	c.lastRequestURLMu.Lock()
+	defer c.lastRequestURLMu.Unlock()
+	return c.lastRequestURL
+}
+
+// RawRequest makes an authenticated request to an arbitrary API path and returns
+// the raw response body and status code without parsing. Retries and error
+// mapping for 4xx/5xx responses are applied as for the typed methods; on error
+// the status code of the last response is returned, or 0 if none was received.
+// This is intended for debugging and for accessing undocumented endpoints.
+func (c *Client) RawRequest(ctx context.Context, method, path string, body interface{}) ([]byte, int, error) {
+	if method == "" {
+		return nil, 0, invalidRequest(errors.New("RawRequest: method is required"))
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, 0, invalidRequest(errors.New("RawRequest: path must start with '/'"))
+	}
+
+	return c.doRequest(ctx, method, path, body, nil, callOptions{operation: "RawRequest"})
+}
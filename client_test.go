@@ -3,8 +3,23 @@
 package memu
 
 import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 // TestNewClient_ValidAPIKey tests client creation with valid API key.
@@ -74,6 +89,26 @@ func TestNewClient_WhitespaceAPIKey(t *testing.T) {
 	}
 }
 
+// TestNewClient_InvalidBaseURL tests that base URLs without an http(s) scheme are rejected.
+func TestNewClient_InvalidBaseURL(t *testing.T) {
+	for _, baseURL := range []string{"api.memu.so", "ftp://api.memu.so", "https://", "://bad"} {
+		_, err := NewClient("test_key", WithBaseURL(baseURL))
+		if err == nil {
+			t.Errorf("expected error for base URL %q, got nil", baseURL)
+			continue
+		}
+		if !strings.Contains(err.Error(), "invalid base URL") {
+			t.Errorf("expected a clear base URL error for %q, got %v", baseURL, err)
+		}
+	}
+
+	for _, baseURL := range []string{"http://localhost:8080", "https://api.memu.so/", "https://gateway.example.com/memu"} {
+		if _, err := NewClient("test_key", WithBaseURL(baseURL)); err != nil {
+			t.Errorf("expected base URL %q to be accepted, got %v", baseURL, err)
+		}
+	}
+}
+
 // TestNewClient_Options tests client configuration options.
 func TestNewClient_WithTimeout(t *testing.T) {
 	client, err := NewClient("test_key", WithTimeout(30*time.Second))
@@ -95,6 +130,49 @@ func TestNewClient_WithMaxRetries(t *testing.T) {
 	}
 }
 
+func TestNewClient_WithRegion(t *testing.T) {
+	tests := []struct {
+		region   string
+		expected string
+	}{
+		{"us", "https://api.memu.so"},
+		{"eu", "https://eu.api.memu.so"},
+		{"EU", "https://eu.api.memu.so"},
+	}
+
+	for _, tt := range tests {
+		client, err := NewClient("test_key", WithRegion(tt.region))
+		if err != nil {
+			t.Fatalf("NewClient failed for region %q: %v", tt.region, err)
+		}
+		if client.baseURL != tt.expected {
+			t.Errorf("expected baseURL %q for region %q, got %q", tt.expected, tt.region, client.baseURL)
+		}
+	}
+}
+
+func TestNewClient_WithRegion_Unknown(t *testing.T) {
+	_, err := NewClient("test_key", WithRegion("mars"))
+	if err == nil || !strings.Contains(err.Error(), "mars") {
+		t.Fatalf("expected unknown region error, got %v", err)
+	}
+}
+
+func TestNewClient_WithRegion_BaseURLPrecedence(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithRegion("eu"), WithBaseURL("https://custom.example.com")},
+		{WithBaseURL("https://custom.example.com"), WithRegion("eu")},
+	} {
+		client, err := NewClient("test_key", opts...)
+		if err != nil {
+			t.Fatalf("NewClient failed: %v", err)
+		}
+		if client.baseURL != "https://custom.example.com" {
+			t.Errorf("expected WithBaseURL to take precedence, got %q", client.baseURL)
+		}
+	}
+}
+
 func TestNewClient_MultipleOptions(t *testing.T) {
 	client, err := NewClient("test_key",
 		WithBaseURL("https://custom.api.com"),
@@ -157,3 +235,2880 @@ func TestNewClient_Defaults(t *testing.T) {
 		t.Errorf("expected default maxRetries %d, got %d", DefaultMaxRetries, client.maxRetries)
 	}
 }
+
+type requestIDKey struct{}
+
+// TestClient_RequestIDExtractor tests correlation header propagation.
+func TestClient_RequestIDExtractor(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("X-Correlation-Id"))
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient("test_key",
+		WithBaseURL(server.URL),
+		WithRequestIDExtractor(func(ctx context.Context) string {
+			id, _ := ctx.Value(requestIDKey{}).(string)
+			return id
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-123")
+	if _, err := client.GetTaskStatus(ctx, "t1"); err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+	if _, err := client.GetTaskStatus(context.Background(), "t1"); err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(got))
+	}
+	if got[0] != "req-123" {
+		t.Errorf("expected X-Correlation-Id 'req-123', got '%s'", got[0])
+	}
+	if got[1] != "" {
+		t.Errorf("expected no X-Correlation-Id header, got '%s'", got[1])
+	}
+}
+
+// TestBuildRetrievePayload tests Retrieve payload construction.
+func TestBuildRetrievePayload(t *testing.T) {
+	req := &RetrieveRequest{
+		Query:   "What does the user like?",
+		UserID:  "user_123",
+		AgentID: "agent_456",
+	}
+
+	payload := buildRetrievePayload(req)
+	if _, ok := payload["type_weights"]; ok {
+		t.Error("expected type_weights to be omitted when not provided")
+	}
+
+	req.TypeWeights = map[string]float64{"preference": 2.0}
+	payload = buildRetrievePayload(req)
+	weights, ok := payload["type_weights"].(map[string]float64)
+	if !ok {
+		t.Fatalf("expected type_weights in payload, got %v", payload["type_weights"])
+	}
+	if weights["preference"] != 2.0 {
+		t.Errorf("expected preference weight 2.0, got %v", weights["preference"])
+	}
+}
+
+// TestClient_MaxRequestBytes tests client-side request size limits.
+func TestClient_MaxRequestBytes(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"categories":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient("test_key", WithBaseURL(server.URL), WithMaxRequestBytes(64))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	// Under the limit
+	if _, err := client.ListCategories(context.Background(), &ListCategoriesRequest{UserID: "u1"}); err != nil {
+		t.Fatalf("expected request under limit to succeed, got %v", err)
+	}
+
+	// Over the limit
+	_, err = client.Retrieve(context.Background(), &RetrieveRequest{
+		UserID:  "u1",
+		AgentID: "a1",
+		Query:   strings.Repeat("x", 100),
+	})
+	var tooLarge *RequestTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("expected RequestTooLargeError, got %v", err)
+	}
+	if tooLarge.MaxSize != 64 {
+		t.Errorf("expected MaxSize 64, got %d", tooLarge.MaxSize)
+	}
+	if tooLarge.Size <= 64 {
+		t.Errorf("expected Size over 64, got %d", tooLarge.Size)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request to be sent, got %d", requests)
+	}
+}
+
+// TestParseRetrieveResult_MemoriesKey tests that items under "memories" are parsed.
+func TestParseRetrieveResult_MemoriesKey(t *testing.T) {
+	result, err := parseRetrieveResult(defaultCodec, parseResponseBody(defaultCodec, []byte(
+		`{"memories":[{"content":"Likes tea","memory_type":"preference"}]}`,
+	)))
+	if err != nil {
+		t.Fatalf("parseRetrieveResult failed: %v", err)
+	}
+	if len(result.Items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(result.Items))
+	}
+	if result.Items[0].Content == nil || *result.Items[0].Content != "Likes tea" {
+		t.Errorf("expected Content 'Likes tea', got %v", result.Items[0].Content)
+	}
+}
+
+func TestParseRetrieveResult_MergesItemsAndMemories(t *testing.T) {
+	result, err := parseRetrieveResult(defaultCodec, parseResponseBody(defaultCodec, []byte(
+		`{"items":[{"content":"a"}],"memories":[{"content":"b"},{"content":"c"}]}`,
+	)))
+	if err != nil {
+		t.Fatalf("parseRetrieveResult failed: %v", err)
+	}
+	if len(result.Items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(result.Items))
+	}
+	if *result.Items[0].Content != "a" || *result.Items[2].Content != "c" {
+		t.Errorf("expected items in order a, b, c")
+	}
+}
+
+// newSlowServer returns a server that responds after delay and counts requests.
+func newSlowServer(delay time.Duration, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+		}
+		w.Write([]byte(`{}`))
+	}))
+}
+
+// TestClient_MethodTimeouts tests per-operation timeouts with a global fallback.
+func TestClient_MethodTimeouts(t *testing.T) {
+	var requests int32
+	server := newSlowServer(100*time.Millisecond, &requests)
+	defer server.Close()
+
+	client, _ := NewClient("test_key",
+		WithBaseURL(server.URL),
+		WithMaxRetries(0),
+		WithTimeout(50*time.Millisecond),
+		WithMethodTimeouts(map[string]time.Duration{
+			"Ping":     time.Second,
+			"Retrieve": 10 * time.Millisecond,
+		}),
+	)
+
+	// A mapped timeout may exceed the client timeout
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("expected Ping to finish within its 1s timeout, got: %v", err)
+	}
+
+	start := time.Now()
+	_, err := client.Retrieve(context.Background(), NewStringRetrieve("u1", "a1", "food"))
+	if err == nil {
+		t.Error("expected Retrieve to time out")
+	}
+	if elapsed := time.Since(start); elapsed >= 45*time.Millisecond {
+		t.Errorf("expected Retrieve to use its 10ms timeout, took %v", elapsed)
+	}
+
+	// Unmapped operations fall back to the client timeout
+	if _, err := client.ListCategories(context.Background(), &ListCategoriesRequest{UserID: "u1"}); err == nil {
+		t.Error("expected ListCategories to time out after the 50ms client timeout")
+	}
+}
+
+// zeroBackoffPolicy retries any error up to maxRetries without waiting.
+func zeroBackoffPolicy(maxRetries int) RetryPolicy {
+	return NewCustomRetryPolicy(maxRetries,
+		func(attempt int, statusCode int, err error) bool { return true },
+		func(attempt int) time.Duration { return 0 },
+	)
+}
+
+// TestClient_RetryOnTimeout tests retry behavior for transport and caller timeouts.
+func TestClient_RetryOnTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		retry    bool
+		expected int32
+	}{
+		{"enabled", true, 3},
+		{"disabled", false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := newSlowServer(500*time.Millisecond, &requests)
+			defer server.Close()
+
+			client, err := NewClient("test_key",
+				WithBaseURL(server.URL),
+				WithTimeout(20*time.Millisecond),
+				WithRetryPolicy(zeroBackoffPolicy(2)),
+				WithRetryOnTimeout(tt.retry),
+			)
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			if _, err := client.GetTaskStatus(context.Background(), "t1"); err == nil {
+				t.Fatal("expected timeout error, got nil")
+			}
+			if got := atomic.LoadInt32(&requests); got != tt.expected {
+				t.Errorf("expected %d attempts, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestClient_NoRetryOnCallerDeadline(t *testing.T) {
+	var requests int32
+	server := newSlowServer(500*time.Millisecond, &requests)
+	defer server.Close()
+
+	client, err := NewClient("test_key",
+		WithBaseURL(server.URL),
+		WithRetryPolicy(zeroBackoffPolicy(2)),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.GetTaskStatus(ctx, "t1")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("expected 1 attempt, got %d", got)
+	}
+}
+
+// TestClient_Warnings tests parsing response warnings and routing them to the logger.
+func TestClient_Warnings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/retrieve") {
+			w.Write([]byte(`{"items":[],"warnings":["query rewritten",{"message":"index stale"},42]}`))
+			return
+		}
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING","warnings":["truncated conversation"]}`))
+	}))
+	defer server.Close()
+
+	var logs strings.Builder
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithLogger(log.New(&logs, "", 0)))
+	memorized, err := client.Memorize(context.Background(), newAsyncMemorizeRequest())
+	if err != nil {
+		t.Fatalf("Memorize failed: %v", err)
+	}
+	if len(memorized.Warnings) != 1 || memorized.Warnings[0] != "truncated conversation" {
+		t.Errorf("expected Memorize warning, got %v", memorized.Warnings)
+	}
+
+	retrieved, err := client.Retrieve(context.Background(), NewStringRetrieve("u1", "a1", "food"))
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if len(retrieved.Warnings) != 2 || retrieved.Warnings[0] != "query rewritten" || retrieved.Warnings[1] != "index stale" {
+		t.Errorf("expected 2 Retrieve warnings, got %v", retrieved.Warnings)
+	}
+
+	want := "Memorize: server warning: truncated conversation\n" +
+		"Retrieve: server warning: query rewritten\n" +
+		"Retrieve: server warning: index stale\n"
+	if logs.String() != want {
+		t.Errorf("expected logged warnings %q, got %q", want, logs.String())
+	}
+}
+
+// TestClient_WithName tests that the client name reaches logs, hooks and metrics.
+func TestClient_WithName(t *testing.T) {
+	var headers []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Get("X-Client"))
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING","warnings":["truncated conversation"]}`))
+	}))
+	defer server.Close()
+
+	var logs strings.Builder
+	metrics := &namedMetricsRecorder{}
+	client, _ := NewClient("test_key",
+		WithBaseURL(server.URL),
+		WithName("tenant-a"),
+		WithLogger(log.New(&logs, "", 0)),
+		WithMetrics(metrics),
+		WithHeaderFunc(func(ctx context.Context, method, path string) map[string]string {
+			return map[string]string{"X-Client": ClientName(ctx)}
+		}),
+	)
+	if _, err := client.Memorize(context.Background(), newAsyncMemorizeRequest()); err != nil {
+		t.Fatalf("Memorize failed: %v", err)
+	}
+
+	if logs.String() != "[tenant-a] Memorize: server warning: truncated conversation\n" {
+		t.Errorf("expected the name to prefix logged lines, got %q", logs.String())
+	}
+	if headers[0] != "tenant-a" {
+		t.Errorf("expected ClientName to reach hooks, got %q", headers[0])
+	}
+	if len(metrics.clients) != 1 || metrics.clients[0] != "tenant-a" {
+		t.Errorf("expected the name in metric observations, got %v", metrics.clients)
+	}
+	if client.Config().Name != "tenant-a" {
+		t.Errorf("expected Config().Name tenant-a, got %q", client.Config().Name)
+	}
+
+	// Unnamed clients log without a prefix
+	logs.Reset()
+	client, _ = NewClient("test_key", WithBaseURL(server.URL), WithLogger(log.New(&logs, "", 0)))
+	client.Memorize(context.Background(), newAsyncMemorizeRequest())
+	if logs.String() != "Memorize: server warning: truncated conversation\n" {
+		t.Errorf("expected no prefix without a name, got %q", logs.String())
+	}
+}
+
+// namedMetricsRecorder records the client names passed to NamedMetrics.
+type namedMetricsRecorder struct {
+	clients []string
+}
+
+func (m *namedMetricsRecorder) ObserveRequest(method string, statusCode int, duration time.Duration, err error) {
+	m.clients = append(m.clients, "unnamed call")
+}
+
+func (m *namedMetricsRecorder) ObserveRetry(method string, cause RetryCause, statusCode int, err error) {
+}
+
+func (m *namedMetricsRecorder) ObserveNamedRequest(client, method string, statusCode int, duration time.Duration, err error) {
+	m.clients = append(m.clients, client)
+}
+
+func (m *namedMetricsRecorder) ObserveNamedRetry(client, method string, cause RetryCause, statusCode int, err error) {
+}
+
+// TestClient_Memorize_EmptyResponse tests that an empty success body is reported as an error.
+func TestClient_Memorize_EmptyResponse(t *testing.T) {
+	for _, body := range []string{``, `{}`, `{"unrelated":true}`} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}))
+
+		client, _ := NewClient("test_key", WithBaseURL(server.URL))
+		result, err := client.Memorize(context.Background(), newAsyncMemorizeRequest())
+		var respErr *ResponseValidationError
+		if !errors.As(err, &respErr) {
+			t.Errorf("body %q: expected ResponseValidationError, got %v", body, err)
+		} else if respErr.Operation != "Memorize" {
+			t.Errorf("body %q: expected operation Memorize, got %q", body, respErr.Operation)
+		}
+		if result != nil {
+			t.Errorf("body %q: expected nil result, got %+v", body, result)
+		}
+		server.Close()
+	}
+}
+
+// TestClient_RejectDuplicateMessages tests the opt-in duplicate message check.
+func TestClient_RejectDuplicateMessages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	req := &MemorizeRequest{
+		UserID:  "user_123",
+		AgentID: "agent_456",
+		Conversation: []ConversationMessage{
+			{Role: "user", Content: "Hello"},
+			{Role: "user", Content: "Hello"},
+			{Role: "assistant", Content: "Hi"},
+		},
+	}
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	if _, err := client.Memorize(context.Background(), req); err != nil {
+		t.Fatalf("expected duplicates to be allowed by default, got %v", err)
+	}
+
+	client, _ = NewClient("test_key", WithBaseURL(server.URL), WithRejectDuplicateMessages())
+	if _, err := client.Memorize(context.Background(), req); err == nil {
+		t.Fatal("expected error for duplicate messages, got nil")
+	}
+}
+
+// TestClient_RequireChronologicalMessages tests the opt-in CreatedAt ordering check.
+func TestClient_RequireChronologicalMessages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	newRequest := func(createdAt ...*string) *MemorizeRequest {
+		req := &MemorizeRequest{UserID: "user_123", AgentID: "agent_456"}
+		for i, ts := range createdAt {
+			req.Conversation = append(req.Conversation, ConversationMessage{Role: "user", Content: fmt.Sprintf("message %d", i), CreatedAt: ts})
+		}
+		return req
+	}
+	unordered := newRequest(strPtr("2024-01-15T10:00:00Z"), strPtr("2024-01-15T10:05:00Z"), strPtr("2024-01-15T10:01:00Z"))
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	if _, err := client.Memorize(context.Background(), unordered); err != nil {
+		t.Fatalf("expected unordered messages to be allowed by default, got %v", err)
+	}
+
+	client, _ = NewClient("test_key", WithBaseURL(server.URL), WithRequireChronologicalMessages())
+	tests := []struct {
+		name     string
+		req      *MemorizeRequest
+		contains string
+	}{
+		{"ordered", newRequest(strPtr("2024-01-15T10:00:00Z"), strPtr("2024-01-15T10:00:00Z"), strPtr("2024-01-15T10:01:00Z")), ""},
+		{"unordered", unordered, "Conversation[2] is out of order"},
+		{"partially timestamped", newRequest(strPtr("2024-01-15T10:00:00Z"), nil, strPtr("2024-01-15T10:01:00Z")), ""},
+		{"partially timestamped unordered", newRequest(strPtr("2024-01-15T10:05:00Z"), nil, strPtr("2024-01-15T10:01:00Z")), "Conversation[2] is out of order"},
+		{"no timestamps", newRequest(nil, nil, nil), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Memorize(context.Background(), tt.req)
+			if tt.contains == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("expected error containing %q, got %v", tt.contains, err)
+			}
+		})
+	}
+}
+
+// TestParseTimestamp tests parsing RFC3339, epoch and custom layout timestamps.
+func TestParseTimestamp(t *testing.T) {
+	want := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		value  string
+		layout string
+		want   time.Time
+		ok     bool
+	}{
+		{"RFC3339", "2024-01-15T10:30:00Z", "", want, true},
+		{"RFC3339 with offset", "2024-01-15T12:30:00+02:00", "", want, true},
+		{"date only", "2024-01-15", "", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), true},
+		{"epoch seconds", "1705314600", "", want, true},
+		{"epoch seconds with fraction", "1705314600.5", "", want.Add(500 * time.Millisecond), true},
+		{"epoch millis", "1705314600000", "", want, true},
+		{"custom layout", "15/01/2024 10:30", "02/01/2006 15:04", want, true},
+		{"RFC3339 with custom layout", "2024-01-15T10:30:00Z", "02/01/2006 15:04", want, true},
+		{"custom layout without option", "15/01/2024 10:30", "", time.Time{}, false},
+		{"invalid", "yesterday", "", time.Time{}, false},
+		{"exponent", "1e9", "", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseTimestamp(tt.value, tt.layout)
+			if ok != tt.ok || !got.Equal(tt.want) {
+				t.Errorf("parseTimestamp(%q, %q) = %v, %v; want %v, %v", tt.value, tt.layout, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+// TestClient_TimeFormat tests that WithTimeFormat extends timestamp validation.
+func TestClient_TimeFormat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	newRequest := func(createdAt ...string) *MemorizeRequest {
+		req := &MemorizeRequest{UserID: "user_123", AgentID: "agent_456"}
+		for i, ts := range createdAt {
+			req.Conversation = append(req.Conversation, ConversationMessage{Role: "user", Content: fmt.Sprintf("message %d", i), CreatedAt: strPtr(ts)})
+		}
+		return req
+	}
+	custom := newRequest("15/01/2024 10:00", "15/01/2024 10:01", "15/01/2024 10:02")
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	if _, err := client.Memorize(context.Background(), custom); err == nil || !strings.Contains(err.Error(), "is not a valid timestamp") {
+		t.Errorf("expected invalid timestamp error without WithTimeFormat, got %v", err)
+	}
+	if _, err := client.Memorize(context.Background(), newRequest("1705312800", "1705312860000", "2024-01-15T10:02:00Z")); err != nil {
+		t.Errorf("expected epoch and RFC3339 timestamps to be accepted, got %v", err)
+	}
+
+	client, _ = NewClient("test_key", WithBaseURL(server.URL), WithTimeFormat("02/01/2006 15:04"), WithRequireChronologicalMessages())
+	if _, err := client.Memorize(context.Background(), custom); err != nil {
+		t.Errorf("expected custom layout to be accepted, got %v", err)
+	}
+	_, err := client.Memorize(context.Background(), newRequest("15/01/2024 10:05", "1705312800", "2024-01-15T10:06:00Z"))
+	if err == nil || !strings.Contains(err.Error(), "Conversation[1] is out of order") {
+		t.Errorf("expected mixed formats to be compared chronologically, got %v", err)
+	}
+}
+
+// TestClient_MaxConversationMessages tests the opt-in conversation length cap.
+func TestClient_MaxConversationMessages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	newRequest := func(n int) *MemorizeRequest {
+		req := &MemorizeRequest{UserID: "user_123", AgentID: "agent_456"}
+		for i := 0; i < n; i++ {
+			req.Conversation = append(req.Conversation, ConversationMessage{Role: "user", Content: fmt.Sprintf("message %d", i)})
+		}
+		return req
+	}
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	if _, err := client.Memorize(context.Background(), newRequest(50)); err != nil {
+		t.Fatalf("expected no upper bound by default, got %v", err)
+	}
+
+	client, _ = NewClient("test_key", WithBaseURL(server.URL), WithMaxConversationMessages(4))
+	for _, n := range []int{3, 4} {
+		if _, err := client.Memorize(context.Background(), newRequest(n)); err != nil {
+			t.Errorf("expected %d messages to be accepted, got %v", n, err)
+		}
+	}
+	_, err := client.Memorize(context.Background(), newRequest(5))
+	if err == nil || !strings.Contains(err.Error(), "maximum of 4") || !strings.Contains(err.Error(), "chunks") {
+		t.Errorf("expected a cap error suggesting chunking, got %v", err)
+	}
+	// The minimum still applies
+	if _, err := client.Memorize(context.Background(), newRequest(2)); err == nil {
+		t.Error("expected error for fewer than 3 messages, got nil")
+	}
+}
+
+// TestClient_RejectFutureSessionDate tests the opt-in future session date check.
+func TestClient_RejectFutureSessionDate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	fixed := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		sessionDate string
+		wantErr     bool
+	}{
+		{"past", "2024-01-14T12:00:00Z", false},
+		{"within tolerance", "2024-01-15T12:30:00Z", false},
+		{"beyond tolerance", "2024-01-15T14:00:00Z", true},
+		{"date only", "2024-01-15", false},
+		{"future date only", "2024-01-16", true},
+		{"unparseable", "yesterday", true},
+	}
+
+	client, _ := NewClient("test_key",
+		WithBaseURL(server.URL),
+		WithTimeFunc(func() time.Time { return fixed }),
+		WithRejectFutureSessionDate(time.Hour),
+	)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Memorize(context.Background(), &MemorizeRequest{
+				UserID:           "user_123",
+				AgentID:          "agent_456",
+				ConversationText: strPtr("user: I like tea"),
+				SessionDate:      strPtr(tt.sessionDate),
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// Disabled by default
+	client, _ = NewClient("test_key", WithBaseURL(server.URL))
+	if _, err := client.Memorize(context.Background(), &MemorizeRequest{
+		UserID:           "user_123",
+		AgentID:          "agent_456",
+		ConversationText: strPtr("user: I like tea"),
+		SessionDate:      strPtr("2999-01-01"),
+	}); err != nil {
+		t.Errorf("expected future dates to be allowed by default, got %v", err)
+	}
+}
+
+// TestClient_GetMemoryItem tests fetching a single memory item.
+func TestClient_GetMemoryItem(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("user_id") != "user_123" {
+			t.Errorf("expected user_id 'user_123', got '%s'", r.URL.Query().Get("user_id"))
+		}
+		if r.URL.Path != "/api/v3/memory/items/item_1" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"memory item not found"}`))
+			return
+		}
+		w.Write([]byte(`{"item":{"id":"item_1","content":"Likes tea","memory_type":"preference"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient("test_key", WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	item, err := client.GetMemoryItem(context.Background(), &GetMemoryItemRequest{UserID: "user_123", ID: "item_1"})
+	if err != nil {
+		t.Fatalf("GetMemoryItem failed: %v", err)
+	}
+	if item.ID == nil || *item.ID != "item_1" {
+		t.Errorf("expected ID 'item_1', got %v", item.ID)
+	}
+	if item.Content == nil || *item.Content != "Likes tea" {
+		t.Errorf("expected Content 'Likes tea', got %v", item.Content)
+	}
+
+	_, err = client.GetMemoryItem(context.Background(), &GetMemoryItemRequest{UserID: "user_123", ID: "missing"})
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+}
+
+// TestClient_GetMemoryItem_AbsentItem tests that a successful response without
+// an item yields a NotFoundError instead of an empty item.
+func TestClient_GetMemoryItem_AbsentItem(t *testing.T) {
+	for _, body := range []string{``, `{"item":null}`} {
+		t.Run(fmt.Sprintf("body %q", body), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			client, _ := NewClient("test_key", WithBaseURL(server.URL))
+			item, err := client.GetMemoryItem(context.Background(), &GetMemoryItemRequest{UserID: "user_123", ID: "item_1"})
+			var notFound *NotFoundError
+			if !errors.As(err, &notFound) {
+				t.Fatalf("expected NotFoundError, got %v, %+v", err, item)
+			}
+			if !strings.Contains(notFound.Message, "/api/v3/memory/items/item_1") {
+				t.Errorf("expected the message to name the item path, got %q", notFound.Message)
+			}
+		})
+	}
+}
+
+// TestClient_ParseRetryAfter tests Retry-After parsing with an injected clock.
+func TestClient_ParseRetryAfter(t *testing.T) {
+	fixed := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	client, err := NewClient("test_key", WithTimeFunc(func() time.Time { return fixed }))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"2", 2 * time.Second},
+		{"0.5", 500 * time.Millisecond},
+		{fixed.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second},
+		{fixed.Add(-30 * time.Second).Format(http.TimeFormat), 0},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := client.parseRetryAfter(tt.value); got != tt.expected {
+			t.Errorf("parseRetryAfter(%q): expected %v, got %v", tt.value, tt.expected, got)
+		}
+	}
+}
+
+// TestClient_OnRateLimit tests the rate limit callback.
+func TestClient_OnRateLimit(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "0.01")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithOnRateLimit(func(retryAfter time.Duration) {
+		// Runs before the wait, so the retry has not been sent yet
+		if got := atomic.LoadInt32(&requests); got != 1 {
+			t.Errorf("expected callback before retrying, got %d requests", got)
+		}
+		waits = append(waits, retryAfter)
+	}))
+	if _, err := client.GetTaskStatus(context.Background(), "t1"); err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+
+	if len(waits) != 1 || waits[0] != 10*time.Millisecond {
+		t.Errorf("expected one callback with 10ms, got %v", waits)
+	}
+}
+
+// TestClient_Treat403AsRateLimit tests retrying throttling 403 responses.
+func TestClient_Treat403AsRateLimit(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"too many requests"}`))
+			return
+		}
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	// Without the option the 403 is a hard error
+	plain, _ := NewClient("test_key", WithBaseURL(server.URL))
+	if _, err := plain.GetTaskStatus(context.Background(), "t1"); err == nil {
+		t.Fatal("expected 403 to fail without WithTreat403AsRateLimit")
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Fatalf("expected 1 request, got %d", got)
+	}
+
+	atomic.StoreInt32(&requests, 0)
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithTreat403AsRateLimit())
+	status, err := client.GetTaskStatus(context.Background(), "t1")
+	if err != nil {
+		t.Fatalf("expected retry to succeed, got: %v", err)
+	}
+	if status.TaskID != "t1" {
+		t.Errorf("expected TaskID 't1', got %q", status.TaskID)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("expected 2 requests, got %d", got)
+	}
+
+	// Exhausted retries surface as a RateLimitError
+	throttled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer throttled.Close()
+	client, _ = NewClient("test_key", WithBaseURL(throttled.URL), WithTreat403AsRateLimit(), WithMaxRetries(0))
+	_, err = client.GetTaskStatus(context.Background(), "t2")
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Errorf("expected RateLimitError, got %v", err)
+	}
+}
+
+// TestClient_TLSServerName tests verifying a certificate issued for another hostname.
+func TestClient_TLSServerName(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	// Silence the expected handshake failure
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	// The test certificate is valid for example.com but not for localhost
+	baseURL := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	client, _ := NewClient("test_key", WithBaseURL(baseURL), WithHTTPClient(server.Client()), WithMaxRetries(0))
+	if _, err := client.GetTaskStatus(context.Background(), "t1"); err == nil {
+		t.Fatal("expected certificate verification to fail without a server name")
+	}
+
+	client, err := NewClient("test_key", WithBaseURL(baseURL), WithHTTPClient(server.Client()), WithTLSServerName("example.com"))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err := client.GetTaskStatus(context.Background(), "t1"); err != nil {
+		t.Fatalf("expected request to succeed with server name, got: %v", err)
+	}
+	if server.Client().Transport.(*http.Transport).TLSClientConfig.ServerName != "" {
+		t.Error("expected the caller's transport to be left unchanged")
+	}
+}
+
+// TestClient_TryGetTaskStatus tests found, not-found and failing lookups.
+func TestClient_TryGetTaskStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/found"):
+			w.Write([]byte(`{"task_id":"found","status":"PROCESSING"}`))
+		case strings.HasSuffix(r.URL.Path, "/missing"):
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Task not found"}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithMaxRetries(0))
+
+	status, found, err := client.TryGetTaskStatus(context.Background(), "found")
+	if err != nil || !found || status == nil || status.TaskID != "found" {
+		t.Errorf("expected found task, got %v %v %v", status, found, err)
+	}
+
+	status, found, err = client.TryGetTaskStatus(context.Background(), "missing")
+	if err != nil || found || status != nil {
+		t.Errorf("expected (nil, false, nil) for missing task, got %v %v %v", status, found, err)
+	}
+
+	status, found, err = client.TryGetTaskStatus(context.Background(), "forbidden")
+	if err == nil || found || status != nil {
+		t.Errorf("expected error for forbidden task, got %v %v %v", status, found, err)
+	}
+}
+
+// newBlackholeAddr returns a local address whose accept queue is full, so
+// that new connections hang until the dialer times out. The test is skipped
+// when the platform does not drop connections to a full queue.
+func newBlackholeAddr(t *testing.T) string {
+	t.Helper()
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		t.Skipf("cannot create socket: %v", err)
+	}
+	t.Cleanup(func() { syscall.Close(fd) })
+	if err := syscall.Bind(fd, &syscall.SockaddrInet4{Addr: [4]byte{127, 0, 0, 1}}); err != nil {
+		t.Skipf("cannot bind socket: %v", err)
+	}
+	// A backlog of 0 lets the queue fill after at most a few connections
+	if err := syscall.Listen(fd, 0); err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	sa, err := syscall.Getsockname(fd)
+	if err != nil {
+		t.Skipf("cannot read socket address: %v", err)
+	}
+	addr := fmt.Sprintf("127.0.0.1:%d", sa.(*syscall.SockaddrInet4).Port)
+
+	for i := 0; i < 8; i++ {
+		conn, err := net.DialTimeout("tcp", addr, 100*time.Millisecond)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return addr
+			}
+			t.Skipf("unexpected error filling the accept queue: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+	}
+	t.Skip("platform does not drop connections to a full accept queue")
+	return ""
+}
+
+// TestClient_ConnectTimeout tests that a connection that cannot be established
+// fails with a dial timeout well before the request timeout.
+func TestClient_ConnectTimeout(t *testing.T) {
+	addr := newBlackholeAddr(t)
+	client, err := NewClient("test_key",
+		WithBaseURL("http://"+addr),
+		WithTimeout(10*time.Second),
+		WithConnectTimeout(50*time.Millisecond),
+		WithMaxRetries(0),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	start := time.Now()
+	_, err = client.GetTaskStatus(context.Background(), "t1")
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || opErr.Op != "dial" || !opErr.Timeout() {
+		t.Fatalf("expected a dial timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the connect timeout to trip promptly, took %v", elapsed)
+	}
+}
+
+// newCaptureServer returns a server that records the decoded JSON body of each
+// request and responds with response.
+func newCaptureServer(response string, bodies *[]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		*bodies = append(*bodies, body)
+		w.Write([]byte(response))
+	}))
+}
+
+// TestClient_NormalizeIDs tests that normalized IDs are sent when enabled.
+func TestClient_NormalizeIDs(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"task_id":"t1","status":"PENDING"}`, &bodies)
+	defer server.Close()
+
+	req := NewStringRetrieve("User_123", "Agent_456", "food")
+
+	plain, _ := NewClient("test_key", WithBaseURL(server.URL))
+	plain.Retrieve(context.Background(), req)
+	if bodies[0]["user_id"] != "User_123" || bodies[0]["agent_id"] != "Agent_456" {
+		t.Errorf("expected IDs unchanged by default, got %v %v", bodies[0]["user_id"], bodies[0]["agent_id"])
+	}
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithNormalizeIDs(LowercaseID))
+	client.Retrieve(context.Background(), req)
+	client.Memorize(context.Background(), &MemorizeRequest{
+		UserID:           "User_123",
+		AgentID:          "Agent_456",
+		ConversationText: strPtr("user: I like tea"),
+	})
+	agentID := "Agent_456"
+	client.ListCategories(context.Background(), &ListCategoriesRequest{UserID: "User_123", AgentID: &agentID})
+
+	for i, body := range bodies[1:] {
+		if body["user_id"] != "user_123" || body["agent_id"] != "agent_456" {
+			t.Errorf("request %d: expected normalized IDs, got %v %v", i+1, body["user_id"], body["agent_id"])
+		}
+	}
+	if req.UserID != "User_123" {
+		t.Errorf("expected caller's request to be unchanged, got %q", req.UserID)
+	}
+}
+
+// TestClient_RoleAliases tests that aliased roles are rewritten before validation and sending.
+func TestClient_RoleAliases(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"task_id":"t1","status":"PENDING"}`, &bodies)
+	defer server.Close()
+
+	req := &MemorizeRequest{
+		UserID:  "user_123",
+		AgentID: "agent_456",
+		Conversation: []ConversationMessage{
+			{Role: "human", Content: "I like tea"},
+			{Role: "ai", Content: "Green or black?"},
+			{Role: "system", Content: "Be brief"},
+		},
+	}
+
+	plain, _ := NewClient("test_key", WithBaseURL(server.URL))
+	if _, err := plain.Memorize(context.Background(), req); err == nil || !strings.Contains(err.Error(), `Role "human" is not supported`) {
+		t.Fatalf("expected unsupported role error without aliases, got %v", err)
+	}
+
+	aliases := map[string]string{"human": "user", "ai": "assistant"}
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithRoleAliases(aliases))
+	aliases["system"] = "user" // later changes to the caller's map do not apply
+	if _, err := client.Memorize(context.Background(), req); err != nil {
+		t.Fatalf("Memorize failed: %v", err)
+	}
+	conversation, _ := bodies[0]["conversation"].([]interface{})
+	var roles []string
+	for _, msg := range conversation {
+		roles = append(roles, msg.(map[string]interface{})["role"].(string))
+	}
+	if strings.Join(roles, ",") != "user,assistant,system" {
+		t.Errorf("expected aliased roles with unmapped roles passed through, got %v", roles)
+	}
+	if req.Conversation[0].Role != "human" {
+		t.Errorf("expected caller's request to be unchanged, got %q", req.Conversation[0].Role)
+	}
+
+	query := []ConversationMessage{{Role: "human", Content: "What do I drink?"}}
+	if _, err := client.Retrieve(context.Background(), &RetrieveRequest{UserID: "user_123", AgentID: "agent_456", Query: query}); err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	sent, _ := bodies[1]["query"].([]interface{})
+	if len(sent) != 1 || sent[0].(map[string]interface{})["role"] != "user" {
+		t.Errorf("expected aliased query role, got %v", bodies[1]["query"])
+	}
+	if query[0].Role != "human" {
+		t.Errorf("expected caller's query to be unchanged, got %q", query[0].Role)
+	}
+}
+
+// TestClient_SkipValidation tests that invalid requests are sent as-is when validation is skipped.
+func TestClient_SkipValidation(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"task_id":"t1","status":"PENDING"}`, &bodies)
+	defer server.Close()
+
+	invalid := &MemorizeRequest{
+		UserID:       "user_123",
+		Conversation: []ConversationMessage{{Role: "user", Content: "Hi"}},
+	}
+
+	strict, _ := NewClient("test_key", WithBaseURL(server.URL))
+	if _, err := strict.Memorize(context.Background(), invalid); err == nil {
+		t.Fatal("expected validation error without WithSkipValidation")
+	}
+	if len(bodies) != 0 {
+		t.Fatalf("expected no request to be sent, got %d", len(bodies))
+	}
+
+	lenient, _ := NewClient("test_key", WithBaseURL(server.URL), WithSkipValidation())
+	if _, err := lenient.Memorize(context.Background(), invalid); err != nil {
+		t.Fatalf("expected request to be sent, got: %v", err)
+	}
+	if len(bodies) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(bodies))
+	}
+	if bodies[0]["agent_id"] != "" || len(bodies[0]["conversation"].([]interface{})) != 1 {
+		t.Errorf("expected request to be sent unchanged, got %v", bodies[0])
+	}
+
+	// Nil requests are still rejected
+	if _, err := lenient.Retrieve(context.Background(), nil); err == nil {
+		t.Error("expected error for nil request")
+	}
+}
+
+// TestClient_QueryAsConversation tests wrapping of string queries.
+func TestClient_QueryAsConversation(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{}`, &bodies)
+	defer server.Close()
+
+	conversation := []ConversationMessage{
+		{Role: "user", Content: "What do they like?"},
+		{Role: "assistant", Content: "Several things."},
+	}
+
+	plain, _ := NewClient("test_key", WithBaseURL(server.URL))
+	wrapping, _ := NewClient("test_key", WithBaseURL(server.URL), WithQueryAsConversation())
+	ctx := context.Background()
+
+	plain.Retrieve(ctx, &RetrieveRequest{UserID: "u1", AgentID: "a1", Query: "food"})
+	wrapping.Retrieve(ctx, &RetrieveRequest{UserID: "u1", AgentID: "a1", Query: "food"})
+	wrapping.Retrieve(ctx, &RetrieveRequest{UserID: "u1", AgentID: "a1", Query: conversation})
+
+	if len(bodies) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(bodies))
+	}
+	if bodies[0]["query"] != "food" {
+		t.Errorf("expected unwrapped query 'food', got %v", bodies[0]["query"])
+	}
+	wrapped, ok := bodies[1]["query"].([]interface{})
+	if !ok || len(wrapped) != 1 {
+		t.Fatalf("expected single-message query, got %v", bodies[1]["query"])
+	}
+	message := wrapped[0].(map[string]interface{})
+	if message["role"] != "user" || message["content"] != "food" {
+		t.Errorf("expected user message 'food', got %v", message)
+	}
+	if unchanged, ok := bodies[2]["query"].([]interface{}); !ok || len(unchanged) != 2 {
+		t.Errorf("expected conversation query to be unchanged, got %v", bodies[2]["query"])
+	}
+}
+
+// TestClient_ListCategories_CrossAgent tests listing categories without an agent scope.
+func TestClient_ListCategories_CrossAgent(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"categories":[
+		{"name":"preferences","agent_id":"agent_a"},
+		{"name":"work_life","agent_id":"agent_b"}
+	]}`, &bodies)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	categories, err := client.ListCategories(context.Background(), &ListCategoriesRequest{UserID: "u1"})
+	if err != nil {
+		t.Fatalf("ListCategories failed: %v", err)
+	}
+
+	if _, ok := bodies[0]["agent_id"]; ok {
+		t.Errorf("expected agent_id to be omitted, got %v", bodies[0]["agent_id"])
+	}
+	if len(categories) != 2 {
+		t.Fatalf("expected 2 categories, got %d", len(categories))
+	}
+	if categories[0].AgentID == nil || *categories[0].AgentID != "agent_a" {
+		t.Errorf("expected AgentID 'agent_a', got %v", categories[0].AgentID)
+	}
+	if categories[1].AgentID == nil || *categories[1].AgentID != "agent_b" {
+		t.Errorf("expected AgentID 'agent_b', got %v", categories[1].AgentID)
+	}
+}
+
+func TestClient_ListCategories_AgentScoped(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"categories":[{"name":"preferences"}]}`, &bodies)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	agentID := "agent_a"
+	categories, err := client.ListCategories(context.Background(), &ListCategoriesRequest{UserID: "u1", AgentID: &agentID})
+	if err != nil {
+		t.Fatalf("ListCategories failed: %v", err)
+	}
+
+	if bodies[0]["agent_id"] != "agent_a" {
+		t.Errorf("expected agent_id 'agent_a', got %v", bodies[0]["agent_id"])
+	}
+	if len(categories) != 1 || categories[0].AgentID == nil || *categories[0].AgentID != "agent_a" {
+		t.Errorf("expected category annotated with AgentID 'agent_a'")
+	}
+}
+
+// TestClient_CategoryItems tests that memory items nested under a category are
+// parsed into MemoryCategory.Items by both Retrieve and ListCategories.
+func TestClient_CategoryItems(t *testing.T) {
+	const category = `{"name":"preferences","items":[
+		{"id":"m1","content":"likes tea","memory_type":"preference"},
+		{"id":"m2","content":"works remotely"}
+	]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/categories") {
+			w.Write([]byte(`{"categories":[` + category + `]}`))
+			return
+		}
+		w.Write([]byte(`{"categories":[` + category + `],"items":[],"resources":[]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	result, err := client.Retrieve(context.Background(), NewStringRetrieve("u1", "a1", "drinks"))
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	categories, err := client.ListCategories(context.Background(), &ListCategoriesRequest{UserID: "u1"})
+	if err != nil {
+		t.Fatalf("ListCategories failed: %v", err)
+	}
+
+	for name, got := range map[string][]*MemoryCategory{"Retrieve": result.Categories, "ListCategories": categories} {
+		if len(got) != 1 {
+			t.Fatalf("%s: expected 1 category, got %d", name, len(got))
+		}
+		items := got[0].Items
+		if len(items) != 2 {
+			t.Fatalf("%s: expected 2 nested items, got %d", name, len(items))
+		}
+		if items[0].ID == nil || *items[0].ID != "m1" || items[0].Content == nil || *items[0].Content != "likes tea" {
+			t.Errorf("%s: unexpected first item %+v", name, items[0])
+		}
+		if items[0].MemoryType == nil || *items[0].MemoryType != "preference" {
+			t.Errorf("%s: expected MemoryType 'preference', got %v", name, items[0].MemoryType)
+		}
+		if items[1].Content == nil || *items[1].Content != "works remotely" {
+			t.Errorf("%s: unexpected second item %+v", name, items[1])
+		}
+		if _, ok := got[0].RawExtra["items"]; ok {
+			t.Errorf("%s: expected items not to be collected into RawExtra", name)
+		}
+	}
+}
+
+// TestNewClient_BackoffOptions tests the backoff tuning options.
+func TestNewClient_BackoffOptions(t *testing.T) {
+	client, err := NewClient("test_key",
+		WithBaseDelay(100*time.Millisecond),
+		WithBackoffCap(500*time.Millisecond),
+		WithMaxRetries(5),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		500 * time.Millisecond,
+		500 * time.Millisecond,
+	}
+	for attempt, want := range expected {
+		if got := client.retryPolicy.GetBackoff(attempt); got != want {
+			t.Errorf("attempt %d: expected backoff %v, got %v", attempt, want, got)
+		}
+	}
+
+	if !client.retryPolicy.ShouldRetry(4, 0, errors.New("network error")) {
+		t.Error("expected retry at attempt 4 with MaxRetries 5")
+	}
+	if client.retryPolicy.ShouldRetry(5, 0, errors.New("network error")) {
+		t.Error("expected no retry at attempt 5 with MaxRetries 5")
+	}
+}
+
+func TestNewClient_BackoffOptions_DoNotMutateConfig(t *testing.T) {
+	config := DefaultRetryConfig()
+	_, err := NewClient("test_key",
+		WithRetryPolicy(NewDefaultRetryPolicy(config)),
+		WithBackoffCap(time.Second),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if config.MaxDelay != 32*time.Second {
+		t.Errorf("expected caller config MaxDelay to be unchanged, got %v", config.MaxDelay)
+	}
+}
+
+// TestClient_RawRequest tests raw request access.
+func TestClient_RawRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test_key" {
+			t.Errorf("expected Authorization header, got '%s'", r.Header.Get("Authorization"))
+		}
+		if r.URL.Path == "/api/v3/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"undocumented":true}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+
+	body, status, err := client.RawRequest(context.Background(), "POST", "/api/v3/anything", map[string]string{"a": "b"})
+	if err != nil {
+		t.Fatalf("RawRequest failed: %v", err)
+	}
+	if status != http.StatusAccepted {
+		t.Errorf("expected status 202, got %d", status)
+	}
+	if string(body) != `{"undocumented":true}` {
+		t.Errorf("expected raw body, got '%s'", string(body))
+	}
+
+	_, _, err = client.RawRequest(context.Background(), "GET", "/api/v3/missing", nil)
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+}
+
+// TestClient_GetTaskStatus_EscapesTaskID tests task ID validation and escaping.
+func TestClient_GetTaskStatus_EscapesTaskID(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		w.Write([]byte(`{"task_id":"a/b?c","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+
+	if _, err := client.GetTaskStatus(context.Background(), "a/b?c"); err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+	if len(paths) != 1 || paths[0] != "/api/v3/memory/memorize/status/a%2Fb%3Fc" {
+		t.Errorf("expected escaped task ID in path, got %v", paths)
+	}
+
+	if _, err := client.GetTaskStatus(context.Background(), "task_1\n"); err == nil {
+		t.Fatal("expected error for task ID with newline, got nil")
+	}
+	if len(paths) != 1 {
+		t.Errorf("expected no request for invalid task ID, got %d requests", len(paths))
+	}
+}
+
+// TestClient_Memorize_Modes tests payload and response parsing for both memorize modes.
+func TestClient_Memorize_Modes(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		if body["mode"] == "sync" {
+			w.Write([]byte(`{"status":"SUCCESS","items":[{"content":"Likes tea","memory_type":"preference"}],"categories":[{"name":"preferences","summary":"Likes tea"}]}`))
+			return
+		}
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING","message":"queued"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	req := &MemorizeRequest{
+		UserID:           "user_123",
+		AgentID:          "agent_456",
+		ConversationText: strPtr("user: I like tea"),
+	}
+
+	// Async (default)
+	result, err := client.Memorize(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Memorize failed: %v", err)
+	}
+	if _, ok := bodies[0]["mode"]; ok {
+		t.Errorf("expected mode to be omitted by default, got %v", bodies[0]["mode"])
+	}
+	if result.TaskID == nil || *result.TaskID != "t1" {
+		t.Errorf("expected TaskID 't1', got %v", result.TaskID)
+	}
+	if result.Items != nil || result.Categories != nil {
+		t.Errorf("expected no items or categories in async mode, got %v %v", result.Items, result.Categories)
+	}
+
+	// Sync
+	req.Mode = MemorizeModeSync
+	result, err = client.Memorize(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Memorize failed: %v", err)
+	}
+	if bodies[1]["mode"] != "sync" {
+		t.Errorf("expected mode 'sync', got %v", bodies[1]["mode"])
+	}
+	if result.TaskID != nil {
+		t.Errorf("expected no TaskID in sync mode, got %v", *result.TaskID)
+	}
+	if len(result.Items) != 1 || result.Items[0].Content == nil || *result.Items[0].Content != "Likes tea" {
+		t.Errorf("expected 1 extracted item 'Likes tea', got %v", result.Items)
+	}
+	if len(result.Categories) != 1 || result.Categories[0].Name == nil || *result.Categories[0].Name != "preferences" {
+		t.Errorf("expected 1 affected category 'preferences', got %v", result.Categories)
+	}
+}
+
+// TestClient_ReprocessResource tests re-running extraction on a stored resource.
+func TestClient_ReprocessResource(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"task_id":"t2","status":"PENDING","message":"reprocessing"}`, &bodies)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	result, err := client.ReprocessResource(context.Background(), &ReprocessRequest{
+		UserID:     "user_123",
+		AgentID:    "agent_456",
+		ResourceID: "res_1",
+	})
+	if err != nil {
+		t.Fatalf("ReprocessResource failed: %v", err)
+	}
+	if result.TaskID == nil || *result.TaskID != "t2" {
+		t.Errorf("expected TaskID 't2', got %v", result.TaskID)
+	}
+	if bodies[0]["resource_id"] != "res_1" {
+		t.Errorf("expected resource_id 'res_1', got %v", bodies[0]["resource_id"])
+	}
+	if _, ok := bodies[0]["resource_url"]; ok {
+		t.Errorf("expected resource_url to be omitted, got %v", bodies[0]["resource_url"])
+	}
+}
+
+// TestClient_ReassignMemories tests moving memories between agents.
+func TestClient_ReassignMemories(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"moved_count":42}`, &bodies)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	result, err := client.ReassignMemories(context.Background(), &ReassignRequest{
+		UserID:      "user_123",
+		FromAgentID: "agent_old",
+		ToAgentID:   "agent_new",
+	})
+	if err != nil {
+		t.Fatalf("ReassignMemories failed: %v", err)
+	}
+	if result.MovedCount != 42 {
+		t.Errorf("expected MovedCount 42, got %d", result.MovedCount)
+	}
+	if bodies[0]["user_id"] != "user_123" || bodies[0]["from_agent_id"] != "agent_old" || bodies[0]["to_agent_id"] != "agent_new" {
+		t.Errorf("unexpected payload %v", bodies[0])
+	}
+}
+
+// TestClient_RetrieveSimilar tests retrieving memories similar to a seed item.
+func TestClient_RetrieveSimilar(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"items":[{"id":"item_2","content":"Likes green tea"},{"id":"item_3","content":"Drinks coffee on weekdays"}]}`, &bodies)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	result, err := client.RetrieveSimilar(context.Background(), &RetrieveSimilarRequest{
+		UserID:  "user_123",
+		AgentID: "agent_456",
+		ItemID:  "item_1",
+	})
+	if err != nil {
+		t.Fatalf("RetrieveSimilar failed: %v", err)
+	}
+	if len(result.Items) != 2 || *result.Items[0].ID != "item_2" || *result.Items[1].Content != "Drinks coffee on weekdays" {
+		t.Errorf("unexpected items %+v", result.Items)
+	}
+	if result.Categories == nil || result.Resources == nil {
+		t.Error("expected empty rather than nil slices")
+	}
+	if bodies[0]["item_id"] != "item_1" {
+		t.Errorf("expected item_id item_1, got %v", bodies[0]["item_id"])
+	}
+	if _, ok := bodies[0]["content"]; ok {
+		t.Errorf("expected content to be omitted, got %v", bodies[0]["content"])
+	}
+
+	if _, err := client.RetrieveSimilar(context.Background(), &RetrieveSimilarRequest{UserID: "user_123", AgentID: "agent_456"}); err == nil {
+		t.Error("expected error for a request without a seed")
+	}
+	if len(bodies) != 1 {
+		t.Errorf("expected invalid request not to be sent, got %d requests", len(bodies))
+	}
+}
+
+// TestClient_ValidationReasonScope tests that only Memorize 422s are classified
+// and that the server's message is always kept.
+func TestClient_ValidationReasonScope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		if strings.HasSuffix(r.URL.Path, "/memory/retrieve") {
+			w.Write([]byte(`{"message":"query must have at least 3 messages"}`))
+			return
+		}
+		w.Write([]byte(`{"message":"conversation has too few messages"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithRetryPolicy(NewNoRetryPolicy()))
+
+	_, err := client.Memorize(context.Background(), newAsyncMemorizeRequest())
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %T: %v", err, err)
+	}
+	if validationErr.Reason != ValidationReasonTooFewMessages {
+		t.Errorf("expected too few messages reason for Memorize, got %q", validationErr.Reason)
+	}
+	if !strings.Contains(validationErr.Message, "conversation has too few messages") {
+		t.Errorf("expected the server message to be kept, got %q", validationErr.Message)
+	}
+
+	_, err = client.Retrieve(context.Background(), NewStringRetrieve("user_123", "agent_456", "food"))
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %T: %v", err, err)
+	}
+	if validationErr.Reason != ValidationReasonUnknown {
+		t.Errorf("expected no reason outside Memorize, got %q", validationErr.Reason)
+	}
+	if !strings.Contains(validationErr.Message, "query must have at least 3 messages") || strings.Contains(validationErr.Message, "Memorize") {
+		t.Errorf("expected the server message to be kept, got %q", validationErr.Message)
+	}
+}
+
+// TestClient_ErrorMessageFormat tests that error messages share one format across statuses.
+func TestClient_ErrorMessageFormat(t *testing.T) {
+	tests := []struct {
+		status  int
+		body    string
+		message string
+	}{
+		{400, `{"message":"bad cursor"}`, "ClientError: bad cursor (status=400, path=/api/v3/memory/memorize/status/t1)"},
+		{400, `{}`, "ClientError: Bad Request (status=400, path=/api/v3/memory/memorize/status/t1)"},
+		{401, `{"message":"invalid key"}`, "AuthenticationError: invalid key (status=401, path=/api/v3/memory/memorize/status/t1)"},
+		{404, `{}`, "NotFoundError: Resource not found (status=404, path=/api/v3/memory/memorize/status/t1)"},
+		{422, `{"message":"task_id malformed"}`, "ValidationError: task_id malformed (status=422, path=/api/v3/memory/memorize/status/t1)"},
+		{500, `{"message":"database down"}`, "ClientError: database down (status=500, path=/api/v3/memory/memorize/status/t1)"},
+		{500, `oops`, "ClientError: server error, response: oops (status=500, path=/api/v3/memory/memorize/status/t1)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d %s", tt.status, tt.body), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, _ := NewClient("test_key", WithBaseURL(server.URL), WithRetryPolicy(NewNoRetryPolicy()))
+			_, err := client.GetTaskStatus(context.Background(), "t1")
+			var clientErr *ClientError
+			switch e := err.(type) {
+			case *ClientError:
+				clientErr = e
+			case *AuthenticationError:
+				clientErr = e.ClientError
+			case *NotFoundError:
+				clientErr = e.ClientError
+			case *ValidationError:
+				clientErr = e.ClientError
+			default:
+				t.Fatalf("unexpected error %T: %v", err, err)
+			}
+			if clientErr.Message != tt.message {
+				t.Errorf("expected message %q, got %q", tt.message, clientErr.Message)
+			}
+			if got, want := err.Error(), "MemU API error: "+tt.message; got != want {
+				t.Errorf("expected Error() %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+// TestClient_GzipChunkedResponse tests parsing gzip responses sent chunked without Content-Length.
+func TestClient_GzipChunkedResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(`{"task_id":"t1",`))
+		gz.Flush()
+		w.(http.Flusher).Flush() // forces chunked transfer without Content-Length
+		gz.Write([]byte(`"status":"SUCCESS","message":"done"}`))
+		gz.Close()
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"transport decompression", nil},
+		{"explicit Accept-Encoding", []Option{WithHeaderFunc(func(ctx context.Context, method, path string) map[string]string {
+			return map[string]string{"Accept-Encoding": "gzip"}
+		})}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := NewClient("test_key", append([]Option{WithBaseURL(server.URL)}, tt.opts...)...)
+			status, err := client.GetTaskStatus(context.Background(), "t1")
+			if err != nil {
+				t.Fatalf("GetTaskStatus failed: %v", err)
+			}
+			if status.Status != TaskStatusSuccess || status.Message != "done" {
+				t.Errorf("expected the full gzip body to be parsed, got %+v", status)
+			}
+		})
+	}
+}
+
+// TestClient_MaxResponseBytes tests that oversized chunked responses fail with a
+// typed error, whether gzip is decoded by the transport or by the SDK.
+func TestClient_MaxResponseBytes(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Content-Type", "application/json")
+		var out io.Writer = w
+		if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			defer gz.Close()
+			out = gz
+		}
+		out.Write([]byte(`{"task_id":"t1",`))
+		w.(http.Flusher).Flush() // forces chunked transfer without Content-Length
+		out.Write([]byte(`"status":"SUCCESS","message":"` + strings.Repeat("x", 1000) + `"}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"transport decompression", nil},
+		{"explicit Accept-Encoding", []Option{WithHeaderFunc(func(ctx context.Context, method, path string) map[string]string {
+			return map[string]string{"Accept-Encoding": "gzip"}
+		})}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&attempts, 0)
+			opts := append([]Option{WithBaseURL(server.URL), WithMaxResponseBytes(512)}, tt.opts...)
+			client, _ := NewClient("test_key", opts...)
+			_, err := client.GetTaskStatus(context.Background(), "t1")
+			var tooLarge *ResponseTooLargeError
+			if !errors.As(err, &tooLarge) {
+				t.Fatalf("expected ResponseTooLargeError, got %v", err)
+			}
+			if tooLarge.MaxSize != 512 {
+				t.Errorf("expected MaxSize 512, got %d", tooLarge.MaxSize)
+			}
+			if got := atomic.LoadInt32(&attempts); got != 1 {
+				t.Errorf("expected no retries, got %d attempts", got)
+			}
+
+			client, _ = NewClient("test_key", append(opts, WithMaxResponseBytes(2048))...)
+			if _, err := client.GetTaskStatus(context.Background(), "t1"); err != nil {
+				t.Errorf("expected response within the limit to succeed, got %v", err)
+			}
+		})
+	}
+}
+
+// TestReadResponseBody_EmptyGzip tests that an empty gzip-labeled body reads as empty.
+func TestReadResponseBody_EmptyGzip(t *testing.T) {
+	resp := &http.Response{
+		Header: http.Header{"Content-Encoding": []string{"gzip"}},
+		Body:   io.NopCloser(strings.NewReader("")),
+	}
+	body, err := readResponseBody(resp, DefaultMaxResponseBytes)
+	if err != nil || len(body) != 0 {
+		t.Errorf("expected an empty body, got %q, %v", body, err)
+	}
+}
+
+// TestClient_UnexpectedContentType tests that HTML error pages yield a typed error.
+func TestClient_UnexpectedContentType(t *testing.T) {
+	const page = `<html>
+<head><title>502 Bad Gateway</title></head>
+<body><center><h1>502 Bad Gateway</h1></center></body>
+</html>`
+
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithRetryPolicy(zeroBackoffPolicy(2)))
+	_, err := client.GetTaskStatus(context.Background(), "t1")
+	var contentTypeErr *UnexpectedContentTypeError
+	if !errors.As(err, &contentTypeErr) {
+		t.Fatalf("expected UnexpectedContentTypeError, got %T: %v", err, err)
+	}
+	if atomic.LoadInt32(&attempts) != 3 {
+		t.Errorf("expected the 502 to be retried, got %d attempts", attempts)
+	}
+	if contentTypeErr.StatusCode != http.StatusBadGateway || contentTypeErr.ContentType != "text/html; charset=utf-8" ||
+		contentTypeErr.Path != "/api/v3/memory/memorize/status/t1" {
+		t.Errorf("unexpected error fields %+v", contentTypeErr)
+	}
+	if !strings.HasPrefix(contentTypeErr.Snippet, "<html> <head><title>502 Bad Gateway</title></head>") {
+		t.Errorf("expected a whitespace-collapsed snippet, got %q", contentTypeErr.Snippet)
+	}
+	if !strings.Contains(err.Error(), `expected a JSON response but got "text/html; charset=utf-8"`) {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
+
+// TestClient_UnexpectedContentType_Success tests HTML pages served with a success status.
+func TestClient_UnexpectedContentType_Success(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantErr     bool
+	}{
+		{"html page", "text/html", "<!DOCTYPE html><html><body>Please sign in</body></html>", true},
+		{"long html page", "application/xhtml+xml", "<html>" + strings.Repeat("x", 500) + "</html>", true},
+		{"json labeled as html", "text/html", `{"task_id":"t1","status":"SUCCESS"}`, false},
+		{"json", "application/json", `{"task_id":"t1","status":"SUCCESS"}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, _ := NewClient("test_key", WithBaseURL(server.URL))
+			_, err := client.GetTaskStatus(context.Background(), "t1")
+			var contentTypeErr *UnexpectedContentTypeError
+			if got := errors.As(err, &contentTypeErr); got != tt.wantErr {
+				t.Fatalf("expected UnexpectedContentTypeError=%v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr && utf8.RuneCountInString(contentTypeErr.Snippet) > contentTypeSnippetRunes+len("...") {
+				t.Errorf("expected a truncated snippet, got %d runes", utf8.RuneCountInString(contentTypeErr.Snippet))
+			}
+		})
+	}
+}
+
+// TestClient_APIVersion tests reading the server version and pinning the path prefix.
+func TestClient_APIVersion(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/api/version" {
+			w.Write([]byte(`{"version":"v4"}`))
+			return
+		}
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	version, err := client.APIVersion(context.Background())
+	if err != nil {
+		t.Fatalf("APIVersion failed: %v", err)
+	}
+	if version != "v4" {
+		t.Errorf("expected version v4, got %q", version)
+	}
+
+	client.GetTaskStatus(context.Background(), "t1")
+	if paths[1] != "/api/v3/memory/memorize/status/t1" {
+		t.Errorf("expected default v3 path, got %s", paths[1])
+	}
+
+	client, _ = NewClient("test_key", WithBaseURL(server.URL), WithAPIVersion("v4"))
+	client.GetTaskStatus(context.Background(), "t1")
+	if paths[2] != "/api/v4/memory/memorize/status/t1" {
+		t.Errorf("expected configured v4 path, got %s", paths[2])
+	}
+}
+
+// TestClient_APIVersion_AllMethods tests that every method uses the configured version prefix.
+func TestClient_APIVersion_AllMethods(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING","results":[{"task_id":"t2"}]}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	memorizeReq := newAsyncMemorizeRequest()
+	calls := map[string]func(c *Client){
+		"Memorize":      func(c *Client) { c.Memorize(ctx, memorizeReq) },
+		"MemorizeBatch": func(c *Client) { c.MemorizeBatch(ctx, []*MemorizeRequest{memorizeReq}) },
+		"ReprocessResource": func(c *Client) {
+			c.ReprocessResource(ctx, &ReprocessRequest{UserID: "u1", AgentID: "a1", ResourceID: "r1"})
+		},
+		"ReassignMemories": func(c *Client) {
+			c.ReassignMemories(ctx, &ReassignRequest{UserID: "u1", FromAgentID: "a1", ToAgentID: "a2"})
+		},
+		"GetTaskStatus":    func(c *Client) { c.GetTaskStatus(ctx, "t1") },
+		"CancelTask":       func(c *Client) { c.CancelTask(ctx, "t1") },
+		"PurgeFailedTasks": func(c *Client) { c.PurgeFailedTasks(ctx, "u1", "a1") },
+		"ListCategories":   func(c *Client) { c.ListCategories(ctx, &ListCategoriesRequest{UserID: "u1"}) },
+		"ExportCategories": func(c *Client) { c.ExportCategoriesJSONL(ctx, &ListCategoriesRequest{UserID: "u1"}, io.Discard) },
+		"ListMemoryTypes":  func(c *Client) { c.ListMemoryTypes(ctx, "u1", "a1") },
+		"Retrieve":         func(c *Client) { c.Retrieve(ctx, NewStringRetrieve("u1", "a1", "food")) },
+		"RetrieveSimilar": func(c *Client) {
+			c.RetrieveSimilar(ctx, &RetrieveSimilarRequest{UserID: "u1", AgentID: "a1", ItemID: "i1"})
+		},
+		"RecentMemories": func(c *Client) {
+			c.RecentMemories(ctx, &RecentMemoriesRequest{UserID: "u1", AgentID: "a1", Limit: 5})
+		},
+		"GetMemoryItem": func(c *Client) { c.GetMemoryItem(ctx, &GetMemoryItemRequest{UserID: "u1", ID: "i1"}) },
+		"Ping":          func(c *Client) { c.Ping(ctx) },
+	}
+
+	for _, version := range []string{"v4", "/v4/", " v4"} {
+		client, err := NewClient("test_key", WithBaseURL(server.URL), WithAPIVersion(version))
+		if err != nil {
+			t.Fatalf("NewClient failed for version %q: %v", version, err)
+		}
+		for name, call := range calls {
+			paths = nil
+			call(client)
+			if len(paths) == 0 {
+				t.Errorf("%s: expected a request for version %q", name, version)
+				continue
+			}
+			for _, path := range paths {
+				if !strings.HasPrefix(path, "/api/v4/") || strings.Contains(path, "//") {
+					t.Errorf("%s: expected path under /api/v4/ for version %q, got %s", name, version, path)
+				}
+			}
+		}
+	}
+
+	for _, version := range []string{"", "/", "v4/beta"} {
+		if _, err := NewClient("test_key", WithAPIVersion(version)); err == nil {
+			t.Errorf("expected error for API version %q, got nil", version)
+		}
+	}
+}
+
+// TestClient_MaxConcurrentRequests tests that calls beyond the limit wait for a free slot.
+func TestClient_MaxConcurrentRequests(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(30 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithMaxConcurrentRequests(1))
+	start := time.Now()
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := client.GetTaskStatus(context.Background(), "t1")
+			errs <- err
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("GetTaskStatus failed: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&maxInFlight); got != 1 {
+		t.Errorf("expected calls to serialize, got %d in flight", got)
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("expected serialized calls to take at least 60ms, took %v", elapsed)
+	}
+
+	// A waiting call gives up when its context is done
+	release := make(chan struct{})
+	blocking := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer blocking.Close()
+	defer close(release)
+
+	client, _ = NewClient("test_key", WithBaseURL(blocking.URL), WithMaxConcurrentRequests(1))
+	go client.GetTaskStatus(context.Background(), "t1")
+	time.Sleep(20 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetTaskStatus(ctx, "t2"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded while waiting for a slot, got %v", err)
+	}
+}
+
+// TestClient_TokenRefresh tests retrying a 401 with a refreshed token.
+func TestClient_TokenRefresh(t *testing.T) {
+	var authHeaders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer fresh_key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"token expired"}`))
+			return
+		}
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	refreshes := 0
+	client, _ := NewClient("expired_key",
+		WithBaseURL(server.URL),
+		WithTokenRefresh(func(ctx context.Context) (string, error) {
+			refreshes++
+			return "fresh_key", nil
+		}),
+	)
+
+	if _, err := client.GetTaskStatus(context.Background(), "t1"); err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+	if refreshes != 1 {
+		t.Errorf("expected 1 refresh, got %d", refreshes)
+	}
+	if len(authHeaders) != 2 || authHeaders[0] != "Bearer expired_key" || authHeaders[1] != "Bearer fresh_key" {
+		t.Errorf("expected expired then fresh key, got %v", authHeaders)
+	}
+
+	// Subsequent requests use the refreshed key directly
+	if _, err := client.GetTaskStatus(context.Background(), "t1"); err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+	if refreshes != 1 {
+		t.Errorf("expected no further refresh, got %d", refreshes)
+	}
+	if len(authHeaders) != 3 || authHeaders[2] != "Bearer fresh_key" {
+		t.Errorf("expected fresh key on subsequent request, got %v", authHeaders)
+	}
+}
+
+func TestClient_TokenRefresh_Fails(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"token expired"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("expired_key",
+		WithBaseURL(server.URL),
+		WithTokenRefresh(func(ctx context.Context) (string, error) {
+			return "", errors.New("refresh failed")
+		}),
+	)
+
+	_, err := client.GetTaskStatus(context.Background(), "t1")
+	var authErr *AuthenticationError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("expected AuthenticationError, got %v", err)
+	}
+	if authErr.Message != "AuthenticationError: token expired (status=401, path=/api/v3/memory/memorize/status/t1)" {
+		t.Errorf("expected original message 'token expired', got '%s'", authErr.Message)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
+
+// TestClient_Retrieve_CategoriesOnly tests the categories-only retrieval flag.
+func TestClient_Retrieve_CategoriesOnly(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"categories":[{"name":"preferences"}],"items":[{"content":"x"}]}`, &bodies)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	categoriesOnly := true
+	result, err := client.Retrieve(context.Background(), &RetrieveRequest{
+		UserID:         "u1",
+		AgentID:        "a1",
+		Query:          "food",
+		CategoriesOnly: &categoriesOnly,
+	})
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+
+	if bodies[0]["categories_only"] != true {
+		t.Errorf("expected categories_only true, got %v", bodies[0]["categories_only"])
+	}
+	if len(result.Categories) != 1 {
+		t.Errorf("expected 1 category, got %d", len(result.Categories))
+	}
+	if len(result.Items) != 0 || len(result.Resources) != 0 {
+		t.Errorf("expected no items or resources, got %v %v", result.Items, result.Resources)
+	}
+}
+
+// TestClient_Retrieve_EmptySlices tests that empty results serialize as empty arrays.
+func TestClient_Retrieve_EmptySlices(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"rewritten_query":"food"}`, &bodies)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	result, err := client.Retrieve(context.Background(), NewStringRetrieve("u1", "a1", "food"))
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+
+	data, _ := json.Marshal(result)
+	if string(data) != `{"rewritten_query":"food","categories":[],"items":[],"resources":[]}` {
+		t.Errorf("unexpected JSON shape: %s", data)
+	}
+}
+
+// TestClient_Retrieve_IncludeSources tests requesting source snippets with items.
+func TestClient_Retrieve_IncludeSources(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"items":[{"content":"Likes tea","sources":["user: I like tea","user: green tea mostly"]}]}`, &bodies)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	includeSources := true
+	result, err := client.Retrieve(context.Background(), &RetrieveRequest{
+		UserID:         "u1",
+		AgentID:        "a1",
+		Query:          "drinks",
+		IncludeSources: &includeSources,
+	})
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+
+	if bodies[0]["include_sources"] != true {
+		t.Errorf("expected include_sources true, got %v", bodies[0]["include_sources"])
+	}
+	if len(result.Items) != 1 || len(result.Items[0].Sources) != 2 || result.Items[0].Sources[0] != "user: I like tea" {
+		t.Errorf("expected 2 sources on the item, got %+v", result.Items)
+	}
+	if _, ok := result.Items[0].RawExtra["sources"]; ok {
+		t.Error("expected sources not to be reported in RawExtra")
+	}
+
+	// Unset by default
+	client.Retrieve(context.Background(), NewStringRetrieve("u1", "a1", "drinks"))
+	if _, ok := bodies[1]["include_sources"]; ok {
+		t.Errorf("expected include_sources to be omitted by default, got %v", bodies[1]["include_sources"])
+	}
+}
+
+// TestClient_Retrieve_OffsetLimit tests offset-based paging fields and total parsing.
+func TestClient_Retrieve_OffsetLimit(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"items":[{"content":"Likes tea"}],"total_count":37}`, &bodies)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithPayloadSchemaValidation())
+	req := NewStringRetrieve("u1", "a1", "drinks")
+	offset, limit := 20, 10
+	req.Offset = &offset
+	req.Limit = &limit
+	result, err := client.Retrieve(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if bodies[0]["offset"] != float64(20) || bodies[0]["limit"] != float64(10) {
+		t.Errorf("expected offset 20 and limit 10, got %v and %v", bodies[0]["offset"], bodies[0]["limit"])
+	}
+	if result.TotalCount == nil || *result.TotalCount != 37 {
+		t.Errorf("expected TotalCount 37, got %v", result.TotalCount)
+	}
+
+	// Unset by default
+	result, _ = client.Retrieve(context.Background(), NewStringRetrieve("u1", "a1", "drinks"))
+	if _, ok := bodies[1]["offset"]; ok {
+		t.Errorf("expected offset to be omitted by default, got %v", bodies[1]["offset"])
+	}
+	if _, ok := bodies[1]["limit"]; ok {
+		t.Errorf("expected limit to be omitted by default, got %v", bodies[1]["limit"])
+	}
+
+	negative := -1
+	req.Offset = &negative
+	if _, err := client.Retrieve(context.Background(), req); err == nil || !strings.Contains(err.Error(), "Offset") {
+		t.Errorf("expected Offset error, got %v", err)
+	}
+}
+
+// TestClient_Retrieve_RecencyBoost tests the recency boost payload field and its range.
+func TestClient_Retrieve_RecencyBoost(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"items":[]}`, &bodies)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithPayloadSchemaValidation())
+	for _, boost := range []float64{0, 0.3, 1} {
+		req := NewStringRetrieve("u1", "a1", "plans")
+		req.RecencyBoost = floatPtr(boost)
+		if _, err := client.Retrieve(context.Background(), req); err != nil {
+			t.Fatalf("Retrieve failed for boost %v: %v", boost, err)
+		}
+		if got := bodies[len(bodies)-1]["recency_boost"]; got != boost {
+			t.Errorf("expected recency_boost %v, got %v", boost, got)
+		}
+	}
+
+	client.Retrieve(context.Background(), NewStringRetrieve("u1", "a1", "plans"))
+	if _, ok := bodies[len(bodies)-1]["recency_boost"]; ok {
+		t.Error("expected recency_boost to be omitted by default")
+	}
+
+	for _, boost := range []float64{-0.1, 1.5} {
+		req := NewStringRetrieve("u1", "a1", "plans")
+		req.RecencyBoost = floatPtr(boost)
+		if _, err := client.Retrieve(context.Background(), req); err == nil || !strings.Contains(err.Error(), "RecencyBoost") {
+			t.Errorf("expected RecencyBoost error for %v, got %v", boost, err)
+		}
+	}
+}
+
+// TestClient_RetrieveLenient tests that unparseable elements are dropped and logged.
+func TestClient_RetrieveLenient(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{
+		"categories":[{"name":"food"},{"name":5}],
+		"items":[{"content":"Likes tea"},{"content":42},"oops",{"content":"Likes rice"}],
+		"resources":[{"resource_url":"https://example.com"}]
+	}`, &bodies)
+	defer server.Close()
+
+	// Strict by default
+	strict, _ := NewClient("test_key", WithBaseURL(server.URL))
+	if _, err := strict.Retrieve(context.Background(), NewStringRetrieve("u1", "a1", "food")); err == nil {
+		t.Fatal("expected strict Retrieve to fail on bad elements")
+	}
+
+	var logs strings.Builder
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithRetrieveLenient(), WithLogger(log.New(&logs, "", 0)))
+	result, err := client.Retrieve(context.Background(), NewStringRetrieve("u1", "a1", "food"))
+	if err != nil {
+		t.Fatalf("lenient Retrieve failed: %v", err)
+	}
+	if len(result.Items) != 2 || *result.Items[0].Content != "Likes tea" || *result.Items[1].Content != "Likes rice" {
+		t.Errorf("expected the two valid items to survive, got %+v", result.Items)
+	}
+	if len(result.Categories) != 1 || len(result.Resources) != 1 {
+		t.Errorf("expected 1 category and 1 resource, got %d and %d", len(result.Categories), len(result.Resources))
+	}
+	if result.DroppedElements != 3 {
+		t.Errorf("expected 3 dropped elements, got %d", result.DroppedElements)
+	}
+	for _, want := range []string{"categories[1]", "items[1]", "items[2]"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("expected log to mention %s, got %q", want, logs.String())
+		}
+	}
+}
+
+// TestClient_RetrieveFallback tests re-running an empty Retrieve with the fallback query.
+func TestClient_RetrieveFallback(t *testing.T) {
+	var queries []interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		queries = append(queries, body["query"])
+		if body["query"] == "what does the user like" {
+			w.Write([]byte(`{"items":[{"id":"i1","content":"Likes tea"}]}`))
+			return
+		}
+		w.Write([]byte(`{"items":[]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithRetrieveFallback("what does the user like"))
+
+	result, err := client.Retrieve(context.Background(), NewStringRetrieve("user_123", "agent_456", "favorite green tea brand"))
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if !result.Fallback || len(result.Items) != 1 || *result.Items[0].ID != "i1" {
+		t.Errorf("expected fallback results, got %+v", result)
+	}
+	if len(queries) != 2 || queries[1] != "what does the user like" {
+		t.Errorf("expected primary then fallback query, got %v", queries)
+	}
+
+	queries = nil
+	result, err = client.Retrieve(context.Background(), NewStringRetrieve("user_123", "agent_456", "what does the user like"))
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if result.Fallback || len(result.Items) != 1 {
+		t.Errorf("expected primary results without fallback, got %+v", result)
+	}
+	if len(queries) != 1 {
+		t.Errorf("expected no fallback request, got %d requests", len(queries))
+	}
+
+	queries = nil
+	plain, _ := NewClient("test_key", WithBaseURL(server.URL))
+	result, err = plain.Retrieve(context.Background(), NewStringRetrieve("user_123", "agent_456", "favorite green tea brand"))
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if result.Fallback || len(result.Items) != 0 || len(queries) != 1 {
+		t.Errorf("expected no fallback by default, got %+v after %d requests", result, len(queries))
+	}
+}
+
+// TestClient_Retrieve_ExtraParams tests that extra query parameters reach the request URL.
+func TestClient_Retrieve_ExtraParams(t *testing.T) {
+	var queries []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	req := NewStringRetrieve("u1", "a1", "food")
+	req.ExtraParams = map[string]string{"experiment": "rerank_v2", "debug": "1"}
+	if _, err := client.Retrieve(context.Background(), req); err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if queries[0].Get("experiment") != "rerank_v2" || queries[0].Get("debug") != "1" {
+		t.Errorf("expected extra params in URL, got %v", queries[0])
+	}
+
+	for _, params := range []map[string]string{{"": "x"}, {"experiment": ""}} {
+		req.ExtraParams = params
+		if _, err := client.Retrieve(context.Background(), req); err == nil || !strings.Contains(err.Error(), "ExtraParams") {
+			t.Errorf("expected ExtraParams error for %v, got %v", params, err)
+		}
+	}
+	if len(queries) != 1 {
+		t.Errorf("expected invalid params not to be sent, got %d requests", len(queries))
+	}
+}
+
+// TestClient_LastRequestURL tests capturing the URL of the last request.
+func TestClient_LastRequestURL(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{}`, &bodies)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	if got := client.LastRequestURL(); got != "" {
+		t.Errorf("expected empty URL before any request, got %q", got)
+	}
+
+	req := NewStringRetrieve("u1", "a1", "food")
+	req.ExtraParams = map[string]string{"experiment": "rerank_v2"}
+	client.Retrieve(context.Background(), req)
+	if got, expected := client.LastRequestURL(), server.URL+"/api/v3/memory/retrieve?experiment=rerank_v2"; got != expected {
+		t.Errorf("expected URL %q, got %q", expected, got)
+	}
+
+	client.GetMemoryItem(context.Background(), &GetMemoryItemRequest{UserID: "u1", ID: "m1"})
+	if got, expected := client.LastRequestURL(), server.URL+"/api/v3/memory/items/m1?user_id=u1"; got != expected {
+		t.Errorf("expected URL %q, got %q", expected, got)
+	}
+}
+
+func TestParseRetrieveResult_RewrittenQueryShapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected *string
+	}{
+		{"string", `{"rewritten_query":"food preferences"}`, strPtr("food preferences")},
+		{"object", `{"rewritten_query":{"text":"food preferences","lang":"en"}}`, strPtr("food preferences")},
+		{"object without text", `{"rewritten_query":{"lang":"en"}}`, nil},
+		{"missing", `{}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseRetrieveResult(defaultCodec, parseResponseBody(defaultCodec, []byte(tt.body)))
+			if err != nil {
+				t.Fatalf("parseRetrieveResult failed: %v", err)
+			}
+			if tt.expected == nil {
+				if result.RewrittenQuery != nil {
+					t.Errorf("expected no RewrittenQuery, got %q", *result.RewrittenQuery)
+				}
+				return
+			}
+			if result.RewrittenQuery == nil || *result.RewrittenQuery != *tt.expected {
+				t.Errorf("expected RewrittenQuery %q, got %v", *tt.expected, result.RewrittenQuery)
+			}
+		})
+	}
+}
+
+func TestParseRetrieveResult_CategoriesOnlyBody(t *testing.T) {
+	result, err := parseRetrieveResult(defaultCodec, parseResponseBody(defaultCodec, []byte(`{"categories":[{"name":"preferences"}]}`)))
+	if err != nil {
+		t.Fatalf("parseRetrieveResult failed: %v", err)
+	}
+	if len(result.Categories) != 1 || result.Items != nil || result.Resources != nil {
+		t.Errorf("expected only categories, got %+v", result)
+	}
+}
+
+// TestClient_HeaderFunc tests dynamic per-request headers.
+func TestClient_HeaderFunc(t *testing.T) {
+	var signatures, auths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signatures = append(signatures, r.Header.Get("X-Signature"))
+		auths = append(auths, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"task_id":"t1","status":"SUCCESS"}`))
+	}))
+	defer server.Close()
+
+	headerFunc := func(ctx context.Context, method, path string) map[string]string {
+		return map[string]string{
+			"X-Signature":   method + " " + path,
+			"authorization": "Bearer other_key",
+		}
+	}
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithHeaderFunc(headerFunc))
+	if _, err := client.GetTaskStatus(context.Background(), "t1"); err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+	if signatures[0] != "GET /api/v3/memory/memorize/status/t1" {
+		t.Errorf("expected signature derived from path, got '%s'", signatures[0])
+	}
+	if auths[0] != "Bearer test_key" {
+		t.Errorf("expected Authorization to be preserved, got '%s'", auths[0])
+	}
+
+	client, _ = NewClient("test_key", WithBaseURL(server.URL), WithHeaderFunc(headerFunc), WithAuthorizationOverride())
+	if _, err := client.GetTaskStatus(context.Background(), "t1"); err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+	if auths[1] != "Bearer other_key" {
+		t.Errorf("expected Authorization to be overridden, got '%s'", auths[1])
+	}
+}
+
+// TestClient_Config tests the diagnostics configuration dump.
+func TestClient_Config(t *testing.T) {
+	client, _ := NewClient("mu_secret123",
+		WithBaseURL("https://custom.example.com"),
+		WithTimeout(30*time.Second),
+		WithMaxRetries(5),
+	)
+
+	config := client.Config()
+	if config.BaseURL != "https://custom.example.com" {
+		t.Errorf("expected BaseURL 'https://custom.example.com', got '%s'", config.BaseURL)
+	}
+	if config.Timeout != 30*time.Second {
+		t.Errorf("expected Timeout 30s, got %v", config.Timeout)
+	}
+	if config.MaxRetries != 5 {
+		t.Errorf("expected MaxRetries 5, got %d", config.MaxRetries)
+	}
+	if config.RetryPolicy != "*memu.defaultRetryPolicy" {
+		t.Errorf("expected RetryPolicy '*memu.defaultRetryPolicy', got '%s'", config.RetryPolicy)
+	}
+	if !config.APIKeySet {
+		t.Error("expected APIKeySet to be true")
+	}
+	if config.MaskedAPIKey != "mu_****" {
+		t.Errorf("expected MaskedAPIKey 'mu_****', got '%s'", config.MaskedAPIKey)
+	}
+	if strings.Contains(fmt.Sprintf("%+v", config), "secret123") {
+		t.Error("expected config dump not to contain the API key")
+	}
+}
+
+func TestMaskAPIKey(t *testing.T) {
+	tests := map[string]string{
+		"mu_abc":        "mu_****",
+		"secretkey":     "****",
+		"longprefix_ab": "****",
+		"mu_":           "****",
+	}
+	for key, expected := range tests {
+		if got := maskAPIKey(key); got != expected {
+			t.Errorf("maskAPIKey(%q): expected '%s', got '%s'", key, expected, got)
+		}
+	}
+}
+
+// TestClient_BodyRetryPredicate tests retrying soft failures reported in 200 bodies.
+func TestClient_BodyRetryPredicate(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.Write([]byte(`{"status":"busy"}`))
+			return
+		}
+		w.Write([]byte(`{"task_id":"t1","status":"SUCCESS"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key",
+		WithBaseURL(server.URL),
+		WithRetryPolicy(zeroBackoffPolicy(3)),
+		WithBodyRetryPredicate(func(body map[string]interface{}) bool {
+			return body["status"] == "busy"
+		}),
+	)
+
+	status, err := client.GetTaskStatus(context.Background(), "t1")
+	if err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+	if status.Status != TaskStatusSuccess {
+		t.Errorf("expected status SUCCESS, got %s", status.Status)
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("expected 3 requests, got %d", got)
+	}
+}
+
+// TestClient_TaskStatusMemo tests that terminal task statuses are memoized.
+func TestClient_TaskStatusMemo(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if strings.HasSuffix(r.URL.Path, "/pending") {
+			w.Write([]byte(`{"task_id":"pending","status":"PENDING"}`))
+			return
+		}
+		w.Write([]byte(`{"task_id":"done","status":"SUCCESS"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		status, err := client.GetTaskStatus(ctx, "done")
+		if err != nil {
+			t.Fatalf("GetTaskStatus failed: %v", err)
+		}
+		if status.Status != TaskStatusSuccess {
+			t.Errorf("expected status SUCCESS, got %s", status.Status)
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("expected 1 request for terminal task, got %d", got)
+	}
+
+	client.GetTaskStatus(ctx, "pending")
+	client.GetTaskStatus(ctx, "pending")
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("expected non-terminal task to be refetched, got %d requests", got)
+	}
+
+	// Disabled memo always refetches
+	client, _ = NewClient("test_key", WithBaseURL(server.URL), WithTaskStatusMemo(false))
+	client.GetTaskStatus(ctx, "done")
+	client.GetTaskStatus(ctx, "done")
+	if got := atomic.LoadInt32(&requests); got != 5 {
+		t.Errorf("expected memo to be disabled, got %d requests", got)
+	}
+}
+
+// TestClient_CancelTask_Pending tests cancelling a pending task.
+func TestClient_CancelTask_Pending(t *testing.T) {
+	var method, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		w.Write([]byte(`{"task_id":"t1","status":"CANCELLED","message":"cancelled by user"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	status, err := client.CancelTask(context.Background(), "t1")
+	if err != nil {
+		t.Fatalf("CancelTask failed: %v", err)
+	}
+
+	if method != "POST" || path != "/api/v3/memory/memorize/status/t1/cancel" {
+		t.Errorf("unexpected request %s %s", method, path)
+	}
+	if status.Status != TaskStatusCancelled {
+		t.Errorf("expected status CANCELLED, got %s", status.Status)
+	}
+	if !status.Status.IsTerminal() {
+		t.Error("expected CANCELLED to be terminal")
+	}
+}
+
+// TestClient_CancelTask_AlreadyCompleted tests cancelling a finished task.
+func TestClient_CancelTask_AlreadyCompleted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/cancel") {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"message":"task already finished"}`))
+			return
+		}
+		w.Write([]byte(`{"task_id":"t1","status":"SUCCESS"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithMaxRetries(0))
+	status, err := client.CancelTask(context.Background(), "t1")
+	if err != nil {
+		t.Fatalf("expected no error for completed task, got %v", err)
+	}
+	if status.Status != TaskStatusSuccess {
+		t.Errorf("expected current status SUCCESS, got %s", status.Status)
+	}
+}
+
+// TestClient_CancelTask_Conflict tests that a conflict on a running task is still an error.
+func TestClient_CancelTask_Conflict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/cancel") {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.Write([]byte(`{"task_id":"t1","status":"PROCESSING"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithMaxRetries(0))
+	if _, err := client.CancelTask(context.Background(), "t1"); err == nil {
+		t.Fatal("expected error when a running task cannot be cancelled")
+	}
+	if _, err := client.CancelTask(context.Background(), ""); err == nil {
+		t.Fatal("expected error for empty taskID")
+	}
+}
+
+// newDroppingServer returns a server that closes the connection without
+// responding to the first request and answers later requests normally.
+func newDroppingServer(requests *int32, keys *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*keys = append(*keys, r.Header.Get("Idempotency-Key"))
+		if atomic.AddInt32(requests, 1) == 1 {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+}
+
+// TestClient_Memorize_NonIdempotentRetry tests that Memorize is only replayed
+// after ambiguous network errors when it is safe to do so.
+func TestClient_Memorize_NonIdempotentRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      *string
+		opts     []Option
+		expected int32
+	}{
+		{"no key", nil, nil, 1},
+		{"with key", strPtr("key-123"), nil, 2},
+		{"override", nil, []Option{WithRetryNonIdempotent(true)}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			var keys []string
+			server := newDroppingServer(&requests, &keys)
+			defer server.Close()
+
+			opts := append([]Option{WithBaseURL(server.URL), WithRetryPolicy(zeroBackoffPolicy(3))}, tt.opts...)
+			client, _ := NewClient("test_key", opts...)
+			_, err := client.Memorize(context.Background(), &MemorizeRequest{
+				UserID:           "user_123",
+				AgentID:          "agent_456",
+				ConversationText: strPtr("user: I like tea"),
+				IdempotencyKey:   tt.key,
+			})
+
+			if got := atomic.LoadInt32(&requests); got != tt.expected {
+				t.Errorf("expected %d requests, got %d", tt.expected, got)
+			}
+			if tt.expected == 1 && err == nil {
+				t.Error("expected error when Memorize is not retried")
+			}
+			if tt.expected == 2 && err != nil {
+				t.Errorf("expected retried Memorize to succeed, got %v", err)
+			}
+			if tt.key != nil && keys[0] != *tt.key {
+				t.Errorf("expected Idempotency-Key %q, got %q", *tt.key, keys[0])
+			}
+		})
+	}
+}
+
+// TestClient_Retrieve_RetriesAmbiguousErrors tests that read-only calls retry freely.
+func TestClient_Retrieve_RetriesAmbiguousErrors(t *testing.T) {
+	var requests int32
+	var keys []string
+	server := newDroppingServer(&requests, &keys)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithRetryPolicy(zeroBackoffPolicy(3)))
+	if _, err := client.Retrieve(context.Background(), NewStringRetrieve("u1", "a1", "food")); err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("expected 2 requests, got %d", got)
+	}
+}
+
+// TestClient_ResponseEnvelope tests unwrapping enveloped responses.
+func TestClient_ResponseEnvelope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/retrieve") {
+			w.Write([]byte(`{"data":{"rewritten_query":"food","items":[{"content":"Likes pizza"}]},"meta":{"request_id":"r1"}}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"task_id":"t1","status":"PROCESSING"},"meta":{"request_id":"r2"}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithResponseEnvelope("data"))
+	ctx := context.Background()
+
+	status, err := client.GetTaskStatus(ctx, "t1")
+	if err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+	if status.TaskID != "t1" || status.Status != TaskStatusProcessing {
+		t.Errorf("expected unwrapped task status, got %+v", status)
+	}
+
+	result, err := client.Retrieve(ctx, NewStringRetrieve("u1", "a1", "food"))
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if result.GetRewrittenQuery() != "food" || len(result.Items) != 1 {
+		t.Errorf("expected unwrapped retrieve result, got %+v", result)
+	}
+
+	// Without the option the envelope hides the fields
+	client, _ = NewClient("test_key", WithBaseURL(server.URL))
+	result, _ = client.Retrieve(ctx, NewStringRetrieve("u1", "a1", "food"))
+	if result.GetRewrittenQuery() != "" || len(result.Items) != 0 {
+		t.Errorf("expected enveloped fields to be ignored, got %+v", result)
+	}
+}
+
+// TestClient_RetryableErrorMessages tests retrying 4xx responses by message.
+func TestClient_RetryableErrorMessages(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected int32
+	}{
+		{"matching message", `{"message":"Service TEMPORARILY UNAVAILABLE, retry"}`, 2},
+		{"matching raw body", `temporarily unavailable`, 2},
+		{"non-matching message", `{"message":"invalid user_id"}`, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&requests, 1) == 1 {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(tt.body))
+					return
+				}
+				w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+			}))
+			defer server.Close()
+
+			client, _ := NewClient("test_key",
+				WithBaseURL(server.URL),
+				WithBaseDelay(0),
+				WithRetryableErrorMessages("temporarily unavailable"),
+			)
+			_, err := client.GetTaskStatus(context.Background(), "t1")
+
+			if got := atomic.LoadInt32(&requests); got != tt.expected {
+				t.Errorf("expected %d requests, got %d", tt.expected, got)
+			}
+			if (err == nil) != (tt.expected == 2) {
+				t.Errorf("unexpected error result: %v", err)
+			}
+		})
+	}
+}
+
+// TestClient_PurgeFailedTasks tests deleting failed task records.
+func TestClient_PurgeFailedTasks(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"deleted_count":7}`, &bodies)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	removed, err := client.PurgeFailedTasks(context.Background(), "user_123", "agent_456")
+	if err != nil {
+		t.Fatalf("PurgeFailedTasks failed: %v", err)
+	}
+	if removed != 7 {
+		t.Errorf("expected 7 removed, got %d", removed)
+	}
+	if bodies[0]["user_id"] != "user_123" || bodies[0]["agent_id"] != "agent_456" || bodies[0]["status"] != "FAILED" {
+		t.Errorf("unexpected payload %v", bodies[0])
+	}
+
+	for _, ids := range [][2]string{{"", "agent_456"}, {"user_123", ""}, {"user 123", "agent_456"}} {
+		if _, err := client.PurgeFailedTasks(context.Background(), ids[0], ids[1]); err == nil {
+			t.Errorf("expected error for IDs %q, got nil", ids)
+		}
+	}
+	if len(bodies) != 1 {
+		t.Errorf("expected invalid calls not to be sent, got %d requests", len(bodies))
+	}
+}
+
+// TestClient_RecentMemories tests fetching the latest memory items ordered by recency.
+func TestClient_RecentMemories(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"items": [
+		{"id": "old", "created_at": "2024-01-10T08:00:00Z"},
+		{"id": "undated"},
+		{"id": "newest", "created_at": "2024-01-15T10:30:00Z"},
+		{"id": "middle", "created_at": "1705312800"},
+		{"id": "extra", "created_at": "2023-12-31T00:00:00Z"}
+	]}`, &bodies)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	items, err := client.RecentMemories(context.Background(), &RecentMemoriesRequest{UserID: "user_123", AgentID: "agent_456", Limit: 4})
+	if err != nil {
+		t.Fatalf("RecentMemories failed: %v", err)
+	}
+	var ids []string
+	for _, item := range items {
+		ids = append(ids, *item.ID)
+	}
+	if strings.Join(ids, ",") != "newest,middle,old,extra" {
+		t.Errorf("expected newest first cut to the limit, got %v", ids)
+	}
+	if *items[0].CreatedAt != "2024-01-15T10:30:00Z" {
+		t.Errorf("expected CreatedAt to be parsed, got %q", *items[0].CreatedAt)
+	}
+	if bodies[0]["user_id"] != "user_123" || bodies[0]["agent_id"] != "agent_456" || bodies[0]["limit"] != float64(4) {
+		t.Errorf("unexpected payload %v", bodies[0])
+	}
+
+	items, err = client.RecentMemories(context.Background(), &RecentMemoriesRequest{UserID: "user_123", AgentID: "agent_456"})
+	if err != nil {
+		t.Fatalf("RecentMemories failed: %v", err)
+	}
+	if len(items) != 5 || *items[4].ID != "undated" {
+		t.Errorf("expected all items with undated ones last, got %d items", len(items))
+	}
+	if _, ok := bodies[1]["limit"]; ok {
+		t.Errorf("expected no limit without Limit, got %v", bodies[1]["limit"])
+	}
+
+	if _, err := client.RecentMemories(context.Background(), &RecentMemoriesRequest{UserID: "user_123", AgentID: "agent_456", Limit: -1}); err == nil {
+		t.Error("expected error for a negative Limit")
+	}
+	if len(bodies) != 2 {
+		t.Errorf("expected invalid request not to be sent, got %d requests", len(bodies))
+	}
+}
+
+// TestClient_ListMemoryTypes tests listing distinct memory types.
+func TestClient_ListMemoryTypes(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{
+		"memory_types": ["skill", "preference", ""],
+		"items": [
+			{"memory_type": "fact"},
+			{"memory_type": "preference"},
+			{"content": "untyped"}
+		]
+	}`, &bodies)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	types, err := client.ListMemoryTypes(context.Background(), "user_123", "agent_456")
+	if err != nil {
+		t.Fatalf("ListMemoryTypes failed: %v", err)
+	}
+
+	expected := []string{"fact", "preference", "skill"}
+	if strings.Join(types, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, types)
+	}
+	if bodies[0]["user_id"] != "user_123" || bodies[0]["agent_id"] != "agent_456" {
+		t.Errorf("unexpected payload: %v", bodies[0])
+	}
+
+	for _, ids := range [][2]string{{"", "agent_456"}, {"user_123", ""}, {"user 123", "agent_456"}, {"user_123", "agent\t456"}} {
+		if _, err := client.ListMemoryTypes(context.Background(), ids[0], ids[1]); err == nil {
+			t.Errorf("expected error for IDs %q, got nil", ids)
+		}
+	}
+	if len(bodies) != 1 {
+		t.Errorf("expected invalid calls not to be sent, got %d requests", len(bodies))
+	}
+}
+
+// TestClient_StartupJitter tests that only the first request is delayed.
+func TestClient_StartupJitter(t *testing.T) {
+	var requests int32
+	server := newSlowServer(0, &requests)
+	defer server.Close()
+
+	const jitter = 100 * time.Millisecond
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithStartupJitter(jitter))
+
+	before := time.Now()
+	if _, err := client.ListMemoryTypes(context.Background(), "u1", "a1"); err != nil {
+		t.Fatalf("ListMemoryTypes failed: %v", err)
+	}
+	first := time.Since(before)
+	if client.startupAt.Before(before) || client.startupAt.After(before.Add(jitter)) {
+		t.Errorf("expected startup delay within %v, got %v", jitter, client.startupAt.Sub(before))
+	}
+	if first < client.startupAt.Sub(before) {
+		t.Errorf("expected first request to wait %v, took %v", client.startupAt.Sub(before), first)
+	}
+
+	before = time.Now()
+	if _, err := client.ListMemoryTypes(context.Background(), "u1", "a1"); err != nil {
+		t.Fatalf("ListMemoryTypes failed: %v", err)
+	}
+	if second := time.Since(before); second >= jitter {
+		t.Errorf("expected later request not to be delayed, took %v", second)
+	}
+}
+
+// TestClient_StartupJitter_FrozenClock tests that a fixed WithTimeFunc does not
+// delay requests past the startup jitter.
+func TestClient_StartupJitter_FrozenClock(t *testing.T) {
+	var requests int32
+	server := newSlowServer(0, &requests)
+	defer server.Close()
+
+	const jitter = 50 * time.Millisecond
+	frozen := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithStartupJitter(jitter),
+		WithTimeFunc(func() time.Time { return frozen }))
+
+	if _, err := client.ListMemoryTypes(context.Background(), "u1", "a1"); err != nil {
+		t.Fatalf("ListMemoryTypes failed: %v", err)
+	}
+	before := time.Now()
+	if _, err := client.ListMemoryTypes(context.Background(), "u1", "a1"); err != nil {
+		t.Fatalf("ListMemoryTypes failed: %v", err)
+	}
+	if second := time.Since(before); second >= jitter {
+		t.Errorf("expected later request not to be delayed, took %v", second)
+	}
+}
+
+// TestClient_StartupJitter_Context tests that the startup delay respects the context.
+func TestClient_StartupJitter_Context(t *testing.T) {
+	var requests int32
+	server := newSlowServer(0, &requests)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithStartupJitter(time.Hour))
+	// Pin the delay so the test does not depend on the random draw
+	client.startupOnce.Do(func() { client.startupAt = time.Now().Add(time.Hour) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.ListMemoryTypes(ctx, "u1", "a1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 0 {
+		t.Errorf("expected no request to be sent, got %d", got)
+	}
+}
+
+// TestClient_Retrieve_AutoPaginate tests following next_cursor across pages.
+func TestClient_Retrieve_AutoPaginate(t *testing.T) {
+	var cursors []interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		cursors = append(cursors, body["cursor"])
+		if body["cursor"] == "page2" {
+			w.Write([]byte(`{"items":[{"content":"c"},{"content":"d"}]}`))
+			return
+		}
+		w.Write([]byte(`{"categories":[{"name":"food"}],"items":[{"content":"a"},{"content":"b"}],"next_cursor":"page2"}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected []string
+		requests int
+	}{
+		{"disabled", nil, []string{"a", "b"}, 1},
+		{"all pages", []Option{WithAutoPaginate(10)}, []string{"a", "b", "c", "d"}, 2},
+		{"capped", []Option{WithAutoPaginate(3)}, []string{"a", "b", "c"}, 2},
+		{"first page fills cap", []Option{WithAutoPaginate(2)}, []string{"a", "b"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursors = nil
+			client, _ := NewClient("test_key", append([]Option{WithBaseURL(server.URL)}, tt.opts...)...)
+			result, err := client.Retrieve(context.Background(), NewStringRetrieve("u1", "a1", "food"))
+			if err != nil {
+				t.Fatalf("Retrieve failed: %v", err)
+			}
+
+			var contents []string
+			for _, item := range result.Items {
+				contents = append(contents, *item.Content)
+			}
+			if strings.Join(contents, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected items %v, got %v", tt.expected, contents)
+			}
+			if len(cursors) != tt.requests {
+				t.Errorf("expected %d requests, got %d", tt.requests, len(cursors))
+			}
+			if tt.requests == 2 && cursors[1] != "page2" {
+				t.Errorf("expected second request with cursor 'page2', got %v", cursors[1])
+			}
+			if len(result.Categories) != 1 {
+				t.Errorf("expected categories from the first page, got %d", len(result.Categories))
+			}
+		})
+	}
+}
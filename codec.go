@@ -0,0 +1,31 @@
+// Package memu provides pluggable JSON encoding for the MemU SDK.
+// This file defines the Codec interface and its encoding/json default.
+package memu
+
+import "encoding/json"
+
+// Codec marshals and unmarshals JSON. Implementations must be safe for
+// concurrent use and behave like encoding/json, including honoring the json
+// struct tags and custom UnmarshalJSON methods on the SDK models.
+type Codec interface {
+	// Marshal returns the JSON encoding of v.
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal parses the JSON-encoded data and stores the result in v.
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// jsonCodec is the Codec backed by encoding/json.
+type jsonCodec struct{}
+
+// Marshal implements Codec using json.Marshal.
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal implements Codec using json.Unmarshal.
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// defaultCodec is the Codec used when none is configured.
+var defaultCodec Codec = jsonCodec{}
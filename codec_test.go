@@ -0,0 +1,129 @@
+// Package memu provides unit tests for pluggable JSON encoding.
+// This file validates WithCodec and the default codec.
+package memu
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// recordingCodec wraps encoding/json and counts how often it is used. With
+// indent set it encodes with indentation, so sizes differ from encoding/json.
+type recordingCodec struct {
+	marshals   int32
+	unmarshals int32
+	indent     bool
+}
+
+func (r *recordingCodec) Marshal(v interface{}) ([]byte, error) {
+	atomic.AddInt32(&r.marshals, 1)
+	if r.indent {
+		return json.MarshalIndent(v, "", "  ")
+	}
+	return json.Marshal(v)
+}
+
+func (r *recordingCodec) Unmarshal(data []byte, v interface{}) error {
+	atomic.AddInt32(&r.unmarshals, 1)
+	return json.Unmarshal(data, v)
+}
+
+func TestWithCodec_NilKeepsDefault(t *testing.T) {
+	client, _ := NewClient("test_key", WithCodec(nil))
+	if client.codec != defaultCodec {
+		t.Errorf("expected default codec, got %T", client.codec)
+	}
+}
+
+func TestWithCodec_UsedAndIdentical(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"rewritten_query": "food preferences",
+			"categories": [{"name": "preferences", "summary": "Likes", "weight": 2}],
+			"items": [{"id": "m1", "memory_type": "fact", "content": "Likes pizza", "score": 0.5}]
+		}`))
+	}))
+	defer server.Close()
+
+	req := NewStringRetrieve("user_123", "agent_456", "food")
+	defaultClient, _ := NewClient("test_key", WithBaseURL(server.URL))
+	want, err := defaultClient.Retrieve(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+
+	codec := &recordingCodec{}
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithCodec(codec))
+	got, err := client.Retrieve(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+
+	if atomic.LoadInt32(&codec.marshals) == 0 {
+		t.Error("expected codec to marshal the request body")
+	}
+	if atomic.LoadInt32(&codec.unmarshals) == 0 {
+		t.Error("expected codec to unmarshal the response")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected identical results, got %+v, want %+v", got, want)
+	}
+}
+
+func TestWithCodec_MemorizeSizeLimit(t *testing.T) {
+	var received int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		atomic.StoreInt32(&received, int32(len(body)))
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	req := newAsyncMemorizeRequest()
+	compact, err := req.PayloadSize()
+	if err != nil {
+		t.Fatalf("PayloadSize failed: %v", err)
+	}
+
+	codec := &recordingCodec{indent: true}
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithCodec(codec))
+	size, err := client.PayloadSize(req)
+	if err != nil {
+		t.Fatalf("PayloadSize failed: %v", err)
+	}
+	if size <= compact {
+		t.Fatalf("expected the indenting codec to grow the payload beyond %d bytes, got %d", compact, size)
+	}
+
+	// A limit that fits the compact encoding but not the codec's is exceeded
+	client, _ = NewClient("test_key", WithBaseURL(server.URL), WithCodec(codec),
+		WithMaxRequestBytes(int64(compact)), WithPayloadSchemaValidation())
+	atomic.StoreInt32(&codec.marshals, 0)
+	_, err = client.Memorize(context.Background(), req)
+	var tooLarge *RequestTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("expected RequestTooLargeError, got %v", err)
+	}
+	if tooLarge.Size != int64(size) {
+		t.Errorf("expected reported size %d, got %d", size, tooLarge.Size)
+	}
+	// One marshal for schema validation and one for the body
+	if got := atomic.LoadInt32(&codec.marshals); got != 2 {
+		t.Errorf("expected schema validation and the body to use the codec, got %d marshals", got)
+	}
+
+	client, _ = NewClient("test_key", WithBaseURL(server.URL), WithCodec(codec), WithMaxRequestBytes(int64(size)))
+	if _, err := client.Memorize(context.Background(), req); err != nil {
+		t.Fatalf("Memorize failed: %v", err)
+	}
+	if got := atomic.LoadInt32(&received); int(got) != size {
+		t.Errorf("expected a %d byte body, got %d", size, got)
+	}
+}
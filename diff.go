@@ -0,0 +1,159 @@
+// Package memu provides comparison helpers for the MemU SDK.
+// This file implements diffing retrieval results for regression testing.
+package memu
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RetrieveDiff describes how a RetrieveResult differs from a previous one.
+// Items are matched by ID, or by content for items without an ID; categories
+// are matched by name. Its String method renders a line per difference.
+type RetrieveDiff struct {
+	// AddedItems are items present only in the new result.
+	AddedItems []*MemoryItem
+	// RemovedItems are items present only in the old result.
+	RemovedItems []*MemoryItem
+	// AddedCategories are categories present only in the new result.
+	AddedCategories []*MemoryCategory
+	// RemovedCategories are categories present only in the old result.
+	RemovedCategories []*MemoryCategory
+	// ChangedCategories are categories present in both results whose
+	// description or summary differs.
+	ChangedCategories []CategoryChange
+}
+
+// CategoryChange pairs the old and new versions of a changed category.
+type CategoryChange struct {
+	// Name is the category name.
+	Name string
+	// Before is the category in the old result.
+	Before *MemoryCategory
+	// After is the category in the new result.
+	After *MemoryCategory
+}
+
+// DiffRetrieveResults compares the old result a with the new result b.
+// A nil result is treated as empty. Differences are listed in the order they
+// appear in b (additions and changes) or a (removals).
+func DiffRetrieveResults(a, b *RetrieveResult) RetrieveDiff {
+	if a == nil {
+		a = &RetrieveResult{}
+	}
+	if b == nil {
+		b = &RetrieveResult{}
+	}
+
+	var diff RetrieveDiff
+
+	oldItems := make(map[string]bool)
+	for _, item := range a.Items {
+		oldItems[itemDiffKey(item)] = true
+	}
+	newItems := make(map[string]bool)
+	for _, item := range b.Items {
+		key := itemDiffKey(item)
+		newItems[key] = true
+		if !oldItems[key] {
+			diff.AddedItems = append(diff.AddedItems, item)
+		}
+	}
+	for _, item := range a.Items {
+		if !newItems[itemDiffKey(item)] {
+			diff.RemovedItems = append(diff.RemovedItems, item)
+		}
+	}
+
+	oldCategories := make(map[string]*MemoryCategory)
+	for _, category := range a.Categories {
+		if category != nil {
+			oldCategories[stringValue(category.Name)] = category
+		}
+	}
+	newCategories := make(map[string]bool)
+	for _, category := range b.Categories {
+		if category == nil {
+			continue
+		}
+		name := stringValue(category.Name)
+		newCategories[name] = true
+		before, ok := oldCategories[name]
+		switch {
+		case !ok:
+			diff.AddedCategories = append(diff.AddedCategories, category)
+		case stringValue(before.Description) != stringValue(category.Description) ||
+			stringValue(before.Summary) != stringValue(category.Summary):
+			diff.ChangedCategories = append(diff.ChangedCategories, CategoryChange{Name: name, Before: before, After: category})
+		}
+	}
+	for _, category := range a.Categories {
+		if category != nil && !newCategories[stringValue(category.Name)] {
+			diff.RemovedCategories = append(diff.RemovedCategories, category)
+		}
+	}
+
+	return diff
+}
+
+// Empty reports whether the diff contains no differences.
+func (d RetrieveDiff) Empty() bool {
+	return len(d.AddedItems) == 0 && len(d.RemovedItems) == 0 &&
+		len(d.AddedCategories) == 0 && len(d.RemovedCategories) == 0 &&
+		len(d.ChangedCategories) == 0
+}
+
+// String renders the diff with one line per difference, prefixed with "+"
+// for additions, "-" for removals and "~" for changes, or "no changes".
+func (d RetrieveDiff) String() string {
+	if d.Empty() {
+		return "no changes"
+	}
+	var lines []string
+	for _, item := range d.AddedItems {
+		lines = append(lines, fmt.Sprintf("+ item %s", itemLabel(item)))
+	}
+	for _, item := range d.RemovedItems {
+		lines = append(lines, fmt.Sprintf("- item %s", itemLabel(item)))
+	}
+	for _, category := range d.AddedCategories {
+		lines = append(lines, fmt.Sprintf("+ category %s", stringValue(category.Name)))
+	}
+	for _, category := range d.RemovedCategories {
+		lines = append(lines, fmt.Sprintf("- category %s", stringValue(category.Name)))
+	}
+	for _, change := range d.ChangedCategories {
+		lines = append(lines, fmt.Sprintf("~ category %s", change.Name))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// itemDiffKey identifies an item by ID, falling back to its content.
+func itemDiffKey(item *MemoryItem) string {
+	if item == nil {
+		return ""
+	}
+	if item.ID != nil && *item.ID != "" {
+		return "id:" + *item.ID
+	}
+	return "content:" + stringValue(item.Content)
+}
+
+// itemLabel describes an item for display by its ID and content.
+func itemLabel(item *MemoryItem) string {
+	if item == nil {
+		return "<nil>"
+	}
+	if item.ID != nil && *item.ID != "" {
+		return fmt.Sprintf("%s %q", *item.ID, stringValue(item.Content))
+	}
+	return fmt.Sprintf("%q", stringValue(item.Content))
+}
+
+// stringValue returns the value of s, or "" if s is nil.
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
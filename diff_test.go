@@ -0,0 +1,91 @@
+// Package memu provides unit tests for comparison helpers.
+// This file validates diffing retrieval results.
+package memu
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffRetrieveResults(t *testing.T) {
+	before := &RetrieveResult{
+		Items: []*MemoryItem{
+			{ID: strPtr("m1"), Content: strPtr("Likes tea")},
+			{ID: strPtr("m2"), Content: strPtr("Works remotely")},
+			{Content: strPtr("Has a cat")},
+		},
+		Categories: []*MemoryCategory{
+			{Name: strPtr("preferences"), Summary: strPtr("Likes tea")},
+			{Name: strPtr("work_life"), Summary: strPtr("Remote")},
+		},
+	}
+	after := &RetrieveResult{
+		Items: []*MemoryItem{
+			{ID: strPtr("m1"), Content: strPtr("Likes tea")},
+			{Content: strPtr("Has a cat")},
+			{ID: strPtr("m3"), Content: strPtr("Plays chess")},
+		},
+		Categories: []*MemoryCategory{
+			{Name: strPtr("preferences"), Summary: strPtr("Likes tea and chess")},
+			{Name: strPtr("hobbies"), Summary: strPtr("Chess")},
+		},
+	}
+
+	diff := DiffRetrieveResults(before, after)
+	if len(diff.AddedItems) != 1 || *diff.AddedItems[0].ID != "m3" {
+		t.Errorf("expected added item m3, got %v", diff.AddedItems)
+	}
+	if len(diff.RemovedItems) != 1 || *diff.RemovedItems[0].ID != "m2" {
+		t.Errorf("expected removed item m2, got %v", diff.RemovedItems)
+	}
+	if len(diff.AddedCategories) != 1 || *diff.AddedCategories[0].Name != "hobbies" {
+		t.Errorf("expected added category hobbies, got %v", diff.AddedCategories)
+	}
+	if len(diff.RemovedCategories) != 1 || *diff.RemovedCategories[0].Name != "work_life" {
+		t.Errorf("expected removed category work_life, got %v", diff.RemovedCategories)
+	}
+	if len(diff.ChangedCategories) != 1 || diff.ChangedCategories[0].Name != "preferences" ||
+		*diff.ChangedCategories[0].After.Summary != "Likes tea and chess" {
+		t.Errorf("expected changed category preferences, got %v", diff.ChangedCategories)
+	}
+	if diff.Empty() {
+		t.Error("expected diff not to be empty")
+	}
+
+	out := diff.String()
+	for _, line := range []string{
+		`+ item m3 "Plays chess"`,
+		`- item m2 "Works remotely"`,
+		"+ category hobbies",
+		"- category work_life",
+		"~ category preferences",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("expected output to contain %q, got:\n%s", line, out)
+		}
+	}
+}
+
+func TestDiffRetrieveResults_NoChange(t *testing.T) {
+	result := &RetrieveResult{
+		Items:      []*MemoryItem{{ID: strPtr("m1"), Content: strPtr("Likes tea")}},
+		Categories: []*MemoryCategory{{Name: strPtr("preferences"), Summary: strPtr("Likes tea")}},
+	}
+
+	diff := DiffRetrieveResults(result, result)
+	if !diff.Empty() {
+		t.Errorf("expected empty diff, got:\n%s", diff)
+	}
+	if diff.String() != "no changes" {
+		t.Errorf("expected 'no changes', got %q", diff.String())
+	}
+
+	// Nil results are treated as empty
+	diff = DiffRetrieveResults(nil, result)
+	if len(diff.AddedItems) != 1 || len(diff.AddedCategories) != 1 {
+		t.Errorf("expected everything added against nil, got %+v", diff)
+	}
+	if !DiffRetrieveResults(nil, nil).Empty() {
+		t.Error("expected empty diff for nil results")
+	}
+}
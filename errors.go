@@ -3,9 +3,22 @@
 package memu
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 )
 
+// ErrTransientResponse is passed to RetryPolicy.ShouldRetry when a successful
+// response body is reported as a transient failure by the predicate set with
+// WithBodyRetryPredicate, or when a 4xx response message matches
+// WithRetryableErrorMessages.
+var ErrTransientResponse = errors.New("transient failure reported in response body")
+
+// ErrClientShutdown is delivered by MemorizeAsync once Shutdown has been called.
+var ErrClientShutdown = errors.New("client is shut down")
+
 // ClientError is the base error type for all MemU SDK errors.
 type ClientError struct {
 	// Message is the error message.
@@ -14,16 +27,71 @@ type ClientError struct {
 	StatusCode *int
 	// Response contains the raw API response data.
 	Response map[string]interface{}
+	// normalized reports whether Message already carries the status code.
+	normalized bool
 }
 
 // Error implements the error interface.
 func (e *ClientError) Error() string {
-	if e.StatusCode != nil {
+	if e.StatusCode != nil && !e.normalized {
 		return fmt.Sprintf("MemU API error (status %d): %s", *e.StatusCode, e.Message)
 	}
 	return fmt.Sprintf("MemU API error: %s", e.Message)
 }
 
+// normalize rewrites Message with formatErrorMessage. Error then no longer
+// repeats the status code, which the message already contains.
+func (e *ClientError) normalize(typeName, path string) {
+	statusCode := 0
+	if e.StatusCode != nil {
+		statusCode = *e.StatusCode
+	}
+	e.Message = formatErrorMessage(typeName, e.Message, statusCode, path)
+	e.normalized = true
+}
+
+// formatErrorMessage formats the message of an error built from an API
+// response as "<Type>: <message> (status=<code>, path=<path>)", so that
+// errors read the same regardless of the endpoint or status branch. The path
+// is left out when empty.
+func formatErrorMessage(typeName, message string, statusCode int, path string) string {
+	if path == "" {
+		return fmt.Sprintf("%s: %s (status=%d)", typeName, message, statusCode)
+	}
+	return fmt.Sprintf("%s: %s (status=%d, path=%s)", typeName, message, statusCode, path)
+}
+
+// invalidRequestError marks a client-side validation failure of a request or
+// argument. It fails the same way on every attempt, so RetryCall never
+// retries it. It reads as, and unwraps to, the underlying error.
+type invalidRequestError struct {
+	// err is the validation error.
+	err error
+}
+
+// Error implements the error interface.
+func (e *invalidRequestError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the validation error.
+func (e *invalidRequestError) Unwrap() error {
+	return e.err
+}
+
+// invalidRequest marks err as a client-side validation failure. A nil err
+// stays nil.
+func invalidRequest(err error) error {
+	if err == nil {
+		return nil
+	}
+	var marked *invalidRequestError
+	if errors.As(err, &marked) {
+		return err
+	}
+	return &invalidRequestError{err: err}
+}
+
 // AuthenticationError is raised when API authentication fails (401).
 type AuthenticationError struct {
 	*ClientError
@@ -87,12 +155,41 @@ func NewNotFoundError(path string, statusCode *int, response map[string]interfac
 	}
 }
 
+// ValidationReason identifies why the server rejected a request.
+type ValidationReason string
+
+const (
+	// ValidationReasonUnknown indicates the rejection reason was not recognized.
+	ValidationReasonUnknown ValidationReason = ""
+	// ValidationReasonTooFewMessages indicates the conversation was too short.
+	ValidationReasonTooFewMessages ValidationReason = "too_few_messages"
+	// ValidationReasonUnsupportedLanguage indicates the conversation language is not supported.
+	ValidationReasonUnsupportedLanguage ValidationReason = "unsupported_language"
+)
+
+// Description returns a human-readable explanation of the reason, matching
+// the client-side validation message where there is one, or "" for
+// ValidationReasonUnknown.
+func (r ValidationReason) Description() string {
+	switch r {
+	case ValidationReasonTooFewMessages:
+		return tooFewMessagesMessage
+	case ValidationReasonUnsupportedLanguage:
+		return "Memorize: the conversation language is not supported"
+	default:
+		return ""
+	}
+}
+
 // ValidationError is raised when request validation fails (422).
 type ValidationError struct {
 	*ClientError
+	// Reason is the recognized rejection reason, or ValidationReasonUnknown.
+	// It is only set for Memorize rejections; see ValidationReason.Description.
+	Reason ValidationReason
 }
 
-// NewValidationError creates a new ValidationError.
+// NewValidationError creates a new ValidationError with the server's message.
 func NewValidationError(statusCode *int, response map[string]interface{}) *ValidationError {
 	message := "Request validation failed. Please check your request parameters."
 	if response != nil {
@@ -109,6 +206,50 @@ func NewValidationError(statusCode *int, response map[string]interface{}) *Valid
 	}
 }
 
+// parseValidationReason recognizes the rejection reason of a 422 Memorize
+// response from an explicit "reason" or "code" field, or from the texts in
+// "message", "error" and "detail". It must only be used for Memorize
+// responses, as the texts are matched loosely.
+func parseValidationReason(response map[string]interface{}) ValidationReason {
+	for _, key := range []string{"reason", "code"} {
+		if value, ok := response[key].(string); ok {
+			switch ValidationReason(strings.ToLower(value)) {
+			case ValidationReasonTooFewMessages, ValidationReasonUnsupportedLanguage:
+				return ValidationReason(strings.ToLower(value))
+			}
+		}
+	}
+
+	// Collect free-form texts, including FastAPI-style detail lists
+	var texts []string
+	for _, key := range []string{"message", "error", "detail"} {
+		switch value := response[key].(type) {
+		case string:
+			texts = append(texts, value)
+		case []interface{}:
+			for _, entry := range value {
+				if detail, ok := entry.(map[string]interface{}); ok {
+					if msg, ok := detail["msg"].(string); ok {
+						texts = append(texts, msg)
+					}
+				}
+			}
+		}
+	}
+
+	text := strings.ToLower(strings.Join(texts, " "))
+	switch {
+	case strings.Contains(text, "too few messages"),
+		strings.Contains(text, fmt.Sprintf("at least %d messages", minConversationMessages)):
+		return ValidationReasonTooFewMessages
+	case strings.Contains(text, "language") &&
+		(strings.Contains(text, "unsupported") || strings.Contains(text, "not supported")):
+		return ValidationReasonUnsupportedLanguage
+	default:
+		return ValidationReasonUnknown
+	}
+}
+
 // NewClientError creates a new ClientError.
 func NewClientError(message string, statusCode *int, response map[string]interface{}) *ClientError {
 	return &ClientError{
@@ -117,3 +258,223 @@ func NewClientError(message string, statusCode *int, response map[string]interfa
 		Response:   response,
 	}
 }
+
+// NewAuthError creates an AuthenticationError with status 401 and the given
+// message, or the default message if empty. Its Message has the same format
+// as errors returned by the client. It is intended for tests and mocks.
+func NewAuthError(message string) *AuthenticationError {
+	statusCode := http.StatusUnauthorized
+	err := NewAuthenticationError(&statusCode, nil)
+	if message != "" {
+		err.Message = message
+	}
+	err.normalize("AuthenticationError", "")
+	return err
+}
+
+// NewRateLimit creates a RateLimitError with status 429 and the given
+// Retry-After seconds. Its Message has the same format as errors returned by
+// the client. It is intended for tests and mocks.
+func NewRateLimit(retryAfterSeconds float64) *RateLimitError {
+	statusCode := http.StatusTooManyRequests
+	err := NewRateLimitError("rate limit exceeded", &retryAfterSeconds, &statusCode, nil)
+	err.normalize("RateLimitError", "")
+	return err
+}
+
+// NewNotFound creates a NotFoundError with status 404 for the given path.
+// Its Message has the same format as errors returned by the client. It is
+// intended for tests and mocks.
+func NewNotFound(path string) *NotFoundError {
+	statusCode := http.StatusNotFound
+	err := NewNotFoundError(path, &statusCode, nil)
+	err.Message = "Resource not found"
+	err.normalize("NotFoundError", path)
+	return err
+}
+
+// NewValidationFailed creates a ValidationError with status 422 and the given
+// message, or the default message if empty. Its Message has the same format
+// as errors returned by the client. It is intended for tests and mocks.
+func NewValidationFailed(message string) *ValidationError {
+	statusCode := http.StatusUnprocessableEntity
+	err := NewValidationError(&statusCode, nil)
+	if message != "" {
+		err.Message = message
+	}
+	err.normalize("ValidationError", "")
+	return err
+}
+
+// SchemaValidationError is returned when a request payload fails client-side
+// schema validation. No HTTP request is made when this error is returned.
+type SchemaValidationError struct {
+	// Operation is the SDK method whose payload failed validation.
+	Operation string
+	// Violations lists each schema violation found in the payload.
+	Violations []string
+}
+
+// Error implements the error interface.
+func (e *SchemaValidationError) Error() string {
+	return fmt.Sprintf("%s: payload schema validation failed: %s", e.Operation, strings.Join(e.Violations, "; "))
+}
+
+// NewSchemaValidationError creates a new SchemaValidationError.
+func NewSchemaValidationError(operation string, violations []string) *SchemaValidationError {
+	return &SchemaValidationError{
+		Operation:  operation,
+		Violations: violations,
+	}
+}
+
+// ResponseValidationError is returned when a successful response lacks the
+// data the operation needs, e.g. an empty Memorize response body.
+type ResponseValidationError struct {
+	// Operation is the SDK method whose response was rejected.
+	Operation string
+	// Message describes what is missing from the response.
+	Message string
+	// Response contains the raw API response data.
+	Response map[string]interface{}
+}
+
+// Error implements the error interface.
+func (e *ResponseValidationError) Error() string {
+	return fmt.Sprintf("%s: invalid response: %s", e.Operation, e.Message)
+}
+
+// NewResponseValidationError creates a new ResponseValidationError.
+func NewResponseValidationError(operation, message string, response map[string]interface{}) *ResponseValidationError {
+	return &ResponseValidationError{
+		Operation: operation,
+		Message:   message,
+		Response:  response,
+	}
+}
+
+// UnexpectedContentTypeError is returned when the API answers with an HTML
+// page instead of JSON, typically an error page from a proxy, load balancer or
+// WAF in front of the API. 5xx responses are retried as usual before it is returned.
+type UnexpectedContentTypeError struct {
+	// ContentType is the Content-Type header of the response.
+	ContentType string
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Path is the API path of the request.
+	Path string
+	// Snippet is the start of the response body, with whitespace collapsed.
+	Snippet string
+}
+
+// Error implements the error interface.
+func (e *UnexpectedContentTypeError) Error() string {
+	message := fmt.Sprintf("expected a JSON response but got %q: %s", e.ContentType, e.Snippet)
+	return formatErrorMessage("UnexpectedContentTypeError", message, e.StatusCode, e.Path)
+}
+
+// NewUnexpectedContentTypeError creates a new UnexpectedContentTypeError.
+func NewUnexpectedContentTypeError(contentType string, statusCode int, path, snippet string) *UnexpectedContentTypeError {
+	return &UnexpectedContentTypeError{
+		ContentType: contentType,
+		StatusCode:  statusCode,
+		Path:        path,
+		Snippet:     snippet,
+	}
+}
+
+// RequestTooLargeError is returned when a request body exceeds the size
+// configured with WithMaxRequestBytes. No HTTP request is made.
+type RequestTooLargeError struct {
+	// Size is the marshaled request body size in bytes.
+	Size int64
+	// MaxSize is the configured maximum request body size in bytes.
+	MaxSize int64
+}
+
+// Error implements the error interface.
+func (e *RequestTooLargeError) Error() string {
+	return fmt.Sprintf("request body too large: %d bytes exceeds limit of %d bytes", e.Size, e.MaxSize)
+}
+
+// NewRequestTooLargeError creates a new RequestTooLargeError.
+func NewRequestTooLargeError(size, maxSize int64) *RequestTooLargeError {
+	return &RequestTooLargeError{
+		Size:    size,
+		MaxSize: maxSize,
+	}
+}
+
+// ResponseTooLargeError is returned when a decoded response body exceeds the
+// size configured with WithMaxResponseBytes. The rest of the body is not read.
+type ResponseTooLargeError struct {
+	// MaxSize is the configured maximum response body size in bytes.
+	MaxSize int64
+}
+
+// Error implements the error interface.
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response body too large: exceeds limit of %d bytes", e.MaxSize)
+}
+
+// NewResponseTooLargeError creates a new ResponseTooLargeError.
+func NewResponseTooLargeError(maxSize int64) *ResponseTooLargeError {
+	return &ResponseTooLargeError{
+		MaxSize: maxSize,
+	}
+}
+
+// WaitTimeoutError is returned by WaitForTask when the task does not reach a
+// terminal status before the wait budget or context deadline expires.
+type WaitTimeoutError struct {
+	// TaskID is the ID of the task being waited on.
+	TaskID string
+	// LastStatus is the last status seen before the timeout, if any.
+	LastStatus *TaskStatus
+}
+
+// Error implements the error interface.
+func (e *WaitTimeoutError) Error() string {
+	if e.LastStatus != nil {
+		return fmt.Sprintf("timed out waiting for task %s (last status: %s)", e.TaskID, e.LastStatus.Status)
+	}
+	return fmt.Sprintf("timed out waiting for task %s", e.TaskID)
+}
+
+// Unwrap returns context.DeadlineExceeded so callers can use errors.Is.
+func (e *WaitTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// NewWaitTimeoutError creates a new WaitTimeoutError.
+func NewWaitTimeoutError(taskID string, lastStatus *TaskStatus) *WaitTimeoutError {
+	return &WaitTimeoutError{
+		TaskID:     taskID,
+		LastStatus: lastStatus,
+	}
+}
+
+// WaitCancelledError is returned by WaitForTask when the channel set with
+// WithStopChannel is closed before the task reaches a terminal status.
+type WaitCancelledError struct {
+	// TaskID is the ID of the task being waited on.
+	TaskID string
+	// LastStatus is the last status seen before the wait was stopped, if any.
+	LastStatus *TaskStatus
+}
+
+// Error implements the error interface.
+func (e *WaitCancelledError) Error() string {
+	if e.LastStatus != nil {
+		return fmt.Sprintf("stopped waiting for task %s (last status: %s)", e.TaskID, e.LastStatus.Status)
+	}
+	return fmt.Sprintf("stopped waiting for task %s", e.TaskID)
+}
+
+// NewWaitCancelledError creates a new WaitCancelledError.
+func NewWaitCancelledError(taskID string, lastStatus *TaskStatus) *WaitCancelledError {
+	return &WaitCancelledError{
+		TaskID:     taskID,
+		LastStatus: lastStatus,
+	}
+}
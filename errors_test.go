@@ -3,6 +3,8 @@
 package memu
 
 import (
+	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -212,6 +214,73 @@ func TestValidationError_CustomMessage(t *testing.T) {
 	if err.Message != "user_id is required" {
 		t.Errorf("expected custom message 'user_id is required', got '%s'", err.Message)
 	}
+	if err.Reason != ValidationReasonUnknown {
+		t.Errorf("expected unknown reason, got '%s'", err.Reason)
+	}
+}
+
+func TestValidationError_MemorizeReasons(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		reason ValidationReason
+	}{
+		{
+			name:   "explicit reason",
+			body:   `{"message":"rejected","reason":"TOO_FEW_MESSAGES"}`,
+			reason: ValidationReasonTooFewMessages,
+		},
+		{
+			name:   "message text",
+			body:   `{"message":"Conversation has too few messages to memorize"}`,
+			reason: ValidationReasonTooFewMessages,
+		},
+		{
+			name:   "detail list",
+			body:   `{"detail":[{"loc":["body","conversation"],"msg":"conversation must have at least 3 messages","type":"value_error"}]}`,
+			reason: ValidationReasonTooFewMessages,
+		},
+		{
+			name:   "unsupported language",
+			body:   `{"message":"Language 'xx' is not supported"}`,
+			reason: ValidationReasonUnsupportedLanguage,
+		},
+		{
+			name:   "too short is not enough",
+			body:   `{"message":"Conversation is too short to memorize"}`,
+			reason: ValidationReasonUnknown,
+		},
+		{
+			name:   "unrecognized",
+			body:   `{"message":"agent_id is invalid"}`,
+			reason: ValidationReasonUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.body), &response); err != nil {
+				t.Fatalf("invalid test body: %v", err)
+			}
+			if reason := parseValidationReason(response); reason != tt.reason {
+				t.Errorf("expected reason '%s', got '%s'", tt.reason, reason)
+			}
+		})
+	}
+
+	// The description matches client-side validation
+	clientErr := (&MemorizeRequest{
+		UserID:       "user_123",
+		AgentID:      "agent_456",
+		Conversation: []ConversationMessage{{Role: "user", Content: "Hi"}},
+	}).Validate()
+	if clientErr == nil || clientErr.Error() != ValidationReasonTooFewMessages.Description() {
+		t.Errorf("expected Validate to report '%s', got %v", ValidationReasonTooFewMessages.Description(), clientErr)
+	}
+	if ValidationReasonUnknown.Description() != "" {
+		t.Errorf("expected no description for unknown reason, got %q", ValidationReasonUnknown.Description())
+	}
 }
 
 func TestValidationError_TypeAssertion(t *testing.T) {
@@ -249,3 +318,48 @@ func TestErrorHierarchy(t *testing.T) {
 		})
 	}
 }
+
+// TestErrorHelpers tests the ergonomic error constructors for tests and mocks.
+func TestErrorHelpers(t *testing.T) {
+	var err error = NewAuthError("token expired")
+	var authErr *AuthenticationError
+	if !errors.As(err, &authErr) {
+		t.Fatal("expected NewAuthError to match *AuthenticationError")
+	}
+	if *authErr.StatusCode != 401 || authErr.Message != "AuthenticationError: token expired (status=401)" {
+		t.Errorf("expected 401 'token expired', got %d '%s'", *authErr.StatusCode, authErr.Message)
+	}
+	if NewAuthError("").Message == "" {
+		t.Error("expected default message for empty NewAuthError message")
+	}
+
+	err = NewRateLimit(2.5)
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Fatal("expected NewRateLimit to match *RateLimitError")
+	}
+	if *rateErr.StatusCode != 429 || rateErr.RetryAfter == nil || *rateErr.RetryAfter != 2.5 {
+		t.Errorf("expected 429 with RetryAfter 2.5, got %d %v", *rateErr.StatusCode, rateErr.RetryAfter)
+	}
+
+	err = NewNotFound("/api/v3/memory/items/x")
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatal("expected NewNotFound to match *NotFoundError")
+	}
+	if *notFound.StatusCode != 404 || notFound.Message != "NotFoundError: Resource not found (status=404, path=/api/v3/memory/items/x)" {
+		t.Errorf("expected 404 mentioning path, got %d '%s'", *notFound.StatusCode, notFound.Message)
+	}
+	if got := notFound.Error(); strings.Count(got, "404") != 1 {
+		t.Errorf("expected Error() to mention the status once, got %q", got)
+	}
+
+	err = NewValidationFailed("query too long")
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatal("expected NewValidationFailed to match *ValidationError")
+	}
+	if *validationErr.StatusCode != 422 || validationErr.Message != "ValidationError: query too long (status=422)" {
+		t.Errorf("expected 422 'query too long', got %d '%s'", *validationErr.StatusCode, validationErr.Message)
+	}
+}
@@ -0,0 +1,55 @@
+// Package memu provides conditional request support for the MemU SDK.
+// This file implements the bounded ETag cache used by GetTaskStatus.
+package memu
+
+import "sync"
+
+// DefaultETagCacheSize is the maximum number of task statuses kept by the
+// ETag cache enabled with WithETagCaching.
+const DefaultETagCacheSize = 1000
+
+// etagEntry is a cached task status and the ETag it was served with.
+type etagEntry struct {
+	etag   string
+	status TaskStatus
+}
+
+// etagCache maps task IDs to their last ETag and status. It is safe for
+// concurrent use and evicts the oldest entry once maxEntries is reached.
+type etagCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]etagEntry
+	// order lists task IDs from oldest to newest insertion.
+	order []string
+}
+
+// newETagCache returns an empty cache holding at most maxEntries statuses.
+func newETagCache(maxEntries int) *etagCache {
+	return &etagCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]etagEntry),
+	}
+}
+
+// get returns the cached entry for taskID.
+func (e *etagCache) get(taskID string) (etagEntry, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	entry, ok := e.entries[taskID]
+	return entry, ok
+}
+
+// put stores the status served with etag for taskID.
+func (e *etagCache) put(taskID, etag string, status *TaskStatus) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.entries[taskID]; !ok {
+		if len(e.order) >= e.maxEntries {
+			delete(e.entries, e.order[0])
+			e.order = e.order[1:]
+		}
+		e.order = append(e.order, taskID)
+	}
+	e.entries[taskID] = etagEntry{etag: etag, status: *status.clone()}
+}
@@ -0,0 +1,83 @@
+// Package memu provides unit tests for conditional requests.
+// This file validates ETag caching of task statuses.
+package memu
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestClient_GetTaskStatus_ETagCaching(t *testing.T) {
+	var ifNoneMatch []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"task_id":"t1","status":"PROCESSING","message":"working"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithETagCaching())
+	for i := 0; i < 3; i++ {
+		status, err := client.GetTaskStatus(context.Background(), "t1")
+		if err != nil {
+			t.Fatalf("poll %d: GetTaskStatus failed: %v", i, err)
+		}
+		if status.Status != TaskStatusProcessing || status.Message != "working" {
+			t.Errorf("poll %d: expected cached PROCESSING status, got %+v", i, status)
+		}
+	}
+
+	expected := []string{"", `"v1"`, `"v1"`}
+	if fmt.Sprint(ifNoneMatch) != fmt.Sprint(expected) {
+		t.Errorf("expected If-None-Match %q, got %q", expected, ifNoneMatch)
+	}
+
+	// Without the option no conditional header is sent
+	ifNoneMatch = nil
+	plain, _ := NewClient("test_key", WithBaseURL(server.URL))
+	plain.GetTaskStatus(context.Background(), "t1")
+	plain.GetTaskStatus(context.Background(), "t1")
+	if ifNoneMatch[1] != "" {
+		t.Errorf("expected no If-None-Match by default, got %q", ifNoneMatch[1])
+	}
+}
+
+func TestETagCache_Bounded(t *testing.T) {
+	cache := newETagCache(2)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cache.put(fmt.Sprintf("t%d", i), "etag", &TaskStatus{TaskID: fmt.Sprintf("t%d", i)})
+		}(i)
+	}
+	wg.Wait()
+
+	if len(cache.entries) != 2 || len(cache.order) != 2 {
+		t.Errorf("expected 2 cached entries, got %d (%d ordered)", len(cache.entries), len(cache.order))
+	}
+
+	cache = newETagCache(2)
+	cache.put("a", "e1", &TaskStatus{TaskID: "a"})
+	cache.put("b", "e2", &TaskStatus{TaskID: "b"})
+	cache.put("a", "e3", &TaskStatus{TaskID: "a"})
+	cache.put("c", "e4", &TaskStatus{TaskID: "c"})
+	if _, ok := cache.get("a"); ok {
+		t.Error("expected oldest entry 'a' to be evicted")
+	}
+	if entry, ok := cache.get("b"); !ok || entry.etag != "e2" {
+		t.Errorf("expected entry 'b' with etag e2, got %+v %v", entry, ok)
+	}
+	if entry, ok := cache.get("c"); !ok || entry.etag != "e4" {
+		t.Errorf("expected newest entry 'c' with etag e4, got %+v %v", entry, ok)
+	}
+}
@@ -189,11 +189,7 @@ func main() {
 			}
 
 			// Summary
-			if cat.Summary != nil && *cat.Summary != "" {
-				summary := *cat.Summary
-				if len(summary) > 100 {
-					summary = summary[:100] + "..."
-				}
+			if summary := cat.SummaryPreview(100); summary != "" {
 				fmt.Printf("         📄 %s\n", summary)
 			}
 
@@ -243,10 +239,7 @@ func main() {
 			}
 			content := "(empty)"
 			if item.Content != nil {
-				content = *item.Content
-				if len(content) > 80 {
-					content = content[:80] + "..."
-				}
+				content = item.Preview(80)
 			}
 			fmt.Printf("      %d. [%s] %s\n", i+1, memType, content)
 		}
@@ -262,13 +255,7 @@ func main() {
 				if cat.Name != nil {
 					name = *cat.Name
 				}
-				summary := ""
-				if cat.Summary != nil {
-					summary = *cat.Summary
-					if len(summary) > 60 {
-						summary = summary[:60] + "..."
-					}
-				}
+				summary := cat.SummaryPreview(60)
 				fmt.Printf("      - %s\n", name)
 				if summary != "" {
 					fmt.Printf("        Summary: %s\n", summary)
@@ -374,11 +361,7 @@ func main() {
 					fmt.Printf("      │  Description: %s\n", *cat.Description)
 				}
 
-				if cat.Summary != nil && *cat.Summary != "" {
-					summary := *cat.Summary
-					if len(summary) > 100 {
-						summary = summary[:100] + "..."
-					}
+				if summary := cat.SummaryPreview(100); summary != "" {
 					fmt.Printf("      │  Summary: %s\n", summary)
 				}
 
@@ -399,11 +382,7 @@ func main() {
 				}
 
 				if item.Content != nil {
-					content := *item.Content
-					if len(content) > 150 {
-						content = content[:150] + "..."
-					}
-					fmt.Printf("      │  Content: %s\n", content)
+					fmt.Printf("      │  Content: %s\n", item.Preview(150))
 				}
 
 				fmt.Printf("      └─\n")
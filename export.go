@@ -0,0 +1,73 @@
+// Package memu provides export helpers for the MemU SDK.
+// This file implements streaming memory categories as line-delimited JSON.
+package memu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// flusher is implemented by buffered writers such as *bufio.Writer.
+type flusher interface {
+	Flush() error
+}
+
+// ExportCategoriesJSONL writes the memory categories matching req to w, one
+// JSON object per line. It follows the next_cursor of paginated responses and
+// writes each page as it arrives, flushing w after every page if it has a
+// Flush() error method, so memory use is bounded by the page size.
+// When there are no categories nothing is written.
+func (c *Client) ExportCategoriesJSONL(ctx context.Context, req *ListCategoriesRequest, w io.Writer) error {
+	if req == nil {
+		return invalidRequest(errors.New("ExportCategoriesJSONL: request is required"))
+	}
+	if w == nil {
+		return invalidRequest(errors.New("ExportCategoriesJSONL: writer is required"))
+	}
+
+	if err := c.validate(req); err != nil {
+		return err
+	}
+
+	payload := buildListCategoriesPayload(req)
+	c.normalizePayloadIDs(payload)
+
+	seen := make(map[string]bool)
+	for {
+		response, err := c.request(ctx, "POST", c.apiPath("/memory/categories"), payload, nil, callOptions{operation: "ExportCategoriesJSONL"})
+		if err != nil {
+			return err
+		}
+
+		categories, err := parseCategories(c.codec, response)
+		if err != nil {
+			return err
+		}
+		annotateCategoryAgent(categories, req.AgentID)
+
+		for _, category := range categories {
+			line, err := c.codec.Marshal(category)
+			if err != nil {
+				return fmt.Errorf("failed to marshal category: %w", err)
+			}
+			if _, err := w.Write(append(line, '\n')); err != nil {
+				return fmt.Errorf("failed to write category: %w", err)
+			}
+		}
+		if f, ok := w.(flusher); ok {
+			if err := f.Flush(); err != nil {
+				return fmt.Errorf("failed to flush categories: %w", err)
+			}
+		}
+
+		cursor, _ := response["next_cursor"].(string)
+		// Stop on the last page or if the server repeats a cursor
+		if cursor == "" || seen[cursor] {
+			return nil
+		}
+		seen[cursor] = true
+		payload["cursor"] = cursor
+	}
+}
@@ -0,0 +1,78 @@
+// Package memu provides unit tests for export helpers.
+// This file validates streaming categories as line-delimited JSON.
+package memu
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExportCategoriesJSONL_Pages(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		if body["cursor"] == "c2" {
+			w.Write([]byte(`{"categories":[{"name":"work_life"}]}`))
+			return
+		}
+		w.Write([]byte(`{"categories":[{"name":"preferences"},{"name":"relationships"}],"next_cursor":"c2"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	agentID := "agent_456"
+	err := client.ExportCategoriesJSONL(context.Background(), &ListCategoriesRequest{UserID: "user_123", AgentID: &agentID}, out)
+	if err != nil {
+		t.Fatalf("ExportCategoriesJSONL failed: %v", err)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 page requests, got %d", len(bodies))
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %q", len(lines), buf.String())
+	}
+	names := []string{"preferences", "relationships", "work_life"}
+	for i, line := range lines {
+		var category MemoryCategory
+		if err := json.Unmarshal([]byte(line), &category); err != nil {
+			t.Fatalf("line %d is not valid JSON: %v", i, err)
+		}
+		if category.Name == nil || *category.Name != names[i] {
+			t.Errorf("line %d: expected name %q, got %v", i, names[i], category.Name)
+		}
+		if category.AgentID == nil || *category.AgentID != "agent_456" {
+			t.Errorf("line %d: expected AgentID 'agent_456', got %v", i, category.AgentID)
+		}
+	}
+}
+
+func TestExportCategoriesJSONL_Empty(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"categories":[]}`, &bodies)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	var buf bytes.Buffer
+	if err := client.ExportCategoriesJSONL(context.Background(), &ListCategoriesRequest{UserID: "user_123"}, &buf); err != nil {
+		t.Fatalf("ExportCategoriesJSONL failed: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing written, got %q", buf.String())
+	}
+
+	if err := client.ExportCategoriesJSONL(context.Background(), &ListCategoriesRequest{}, &buf); err == nil {
+		t.Error("expected validation error for missing UserID")
+	}
+}
@@ -0,0 +1,76 @@
+// Package memu provides fuzz tests for response parsing.
+// This file ensures hostile response bodies never cause panics.
+package memu
+
+import (
+	"strings"
+	"testing"
+)
+
+// fuzzSeeds returns the seed corpus shared by the response parsing fuzz targets.
+func fuzzSeeds() []string {
+	return []string{
+		``,
+		`null`,
+		`[]`,
+		`"string"`,
+		`{}`,
+		`{"task_id":"t1","status":"SUCCESS","message":"done"}`,
+		`{"task_id":1,"status":["x"]}`,
+		`{"categories":[{"name":"preferences","summary":"likes"}]}`,
+		`{"categories":[1,"a",null,{"name":2}]}`,
+		`{"items":[{"content":"x","memory_type":"fact"}],"resources":[{"modality":"text"}],"rewritten_query":"q"}`,
+		`{"items":{"content":"x"},"resources":"nope","rewritten_query":{"a":1}}`,
+		`{"n":1e400}`,
+		`{"n":-0.0000000000000000000000000001e-400}`,
+		strings.Repeat(`[`, 20000) + strings.Repeat(`]`, 20000),
+		`{"items":` + strings.Repeat(`[`, 5000) + strings.Repeat(`]`, 5000) + `}`,
+		"<html><body>502 Bad Gateway</body></html>",
+	}
+}
+
+func FuzzParseTaskStatus(f *testing.F) {
+	for _, seed := range fuzzSeeds() {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		status, err := parseTaskStatus(defaultCodec, parseResponseBody(defaultCodec, body))
+		if err == nil && status == nil {
+			t.Fatal("expected non-nil status when no error is returned")
+		}
+	})
+}
+
+func FuzzParseRetrieveResult(f *testing.F) {
+	for _, seed := range fuzzSeeds() {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		result, err := parseRetrieveResult(defaultCodec, parseResponseBody(defaultCodec, body))
+		if err == nil && result == nil {
+			t.Fatal("expected non-nil result when no error is returned")
+		}
+	})
+}
+
+func FuzzParseCategories(f *testing.F) {
+	for _, seed := range fuzzSeeds() {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		parseCategories(defaultCodec, parseResponseBody(defaultCodec, body))
+	})
+}
+
+// TestParseResponseBody_NonObject tests that non-object bodies are preserved as raw.
+func TestParseResponseBody_NonObject(t *testing.T) {
+	for _, body := range []string{`null`, `[1,2]`, `"text"`, `{"n":1e400}`, `not json`} {
+		result := parseResponseBody(defaultCodec, []byte(body))
+		if result["raw"] != body {
+			t.Errorf("expected raw body '%s', got %v", body, result)
+		}
+	}
+	if result := parseResponseBody(defaultCodec, nil); result != nil {
+		t.Errorf("expected nil result for empty body, got %v", result)
+	}
+}
@@ -20,6 +20,9 @@ type MemUClient interface {
 
 	// ListCategories lists all memory categories.
 	ListCategories(ctx context.Context, req *ListCategoriesRequest) ([]*MemoryCategory, error)
+
+	// Ping checks that the API is reachable and the API key is accepted.
+	Ping(ctx context.Context) error
 }
 
 // Ensure Client implements MemUClient interface
@@ -0,0 +1,69 @@
+// Package memu provides unit tests for the client interface.
+// This file validates that Client and mocks satisfy MemUClient.
+package memu
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// mockClient is a MemUClient for tests. Methods that are not overridden
+// panic through the nil embedded interface.
+type mockClient struct {
+	MemUClient
+	pingErr error
+}
+
+// Ping implements MemUClient.
+func (m *mockClient) Ping(ctx context.Context) error {
+	return m.pingErr
+}
+
+// Ensure mockClient implements MemUClient interface
+var _ MemUClient = (*mockClient)(nil)
+
+// checkReady is an example consumer depending only on MemUClient.
+func checkReady(ctx context.Context, client MemUClient) bool {
+	return client.Ping(ctx) == nil
+}
+
+func TestMemUClient_PingMock(t *testing.T) {
+	ctx := context.Background()
+	if !checkReady(ctx, &mockClient{}) {
+		t.Error("expected healthy mock to be ready")
+	}
+	if checkReady(ctx, &mockClient{pingErr: errors.New("down")}) {
+		t.Error("expected failing mock not to be ready")
+	}
+}
+
+func TestClient_Ping(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if r.Header.Get("Authorization") != "Bearer test_key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	var client MemUClient
+	client, _ = NewClient("test_key", WithBaseURL(server.URL))
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if path != "/api/v3/health" {
+		t.Errorf("expected path /api/v3/health, got %s", path)
+	}
+
+	client, _ = NewClient("bad_key", WithBaseURL(server.URL))
+	var authErr *AuthenticationError
+	if err := client.Ping(context.Background()); !errors.As(err, &authErr) {
+		t.Errorf("expected AuthenticationError, got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+module github.com/NevaMind-AI/memU-sdk-go/memuotel
+
+go 1.21
+
+require (
+	github.com/NevaMind-AI/memU-sdk-go v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/sdk v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/otel/metric v1.24.0 // indirect
+	golang.org/x/sys v0.17.0 // indirect
+)
+
+// Build against the SDK in this repository during local development.
+replace github.com/NevaMind-AI/memU-sdk-go => ../
@@ -0,0 +1,60 @@
+// Package memuotel provides OpenTelemetry tracing for the MemU SDK.
+// It is a separate module so the core memu module stays free of the
+// OpenTelemetry dependency.
+package memuotel
+
+import (
+	"context"
+
+	memu "github.com/NevaMind-AI/memU-sdk-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// statusCodeKey is the attribute recording the last HTTP status received.
+	statusCodeKey = attribute.Key("http.response.status_code")
+	// clientNameKey is the attribute recording the memu.WithName client label.
+	clientNameKey = attribute.Key("memu.client.name")
+)
+
+// Tracer implements memu.Tracer with an OpenTelemetry tracer.
+type Tracer struct {
+	// tracer starts the spans.
+	tracer trace.Tracer
+}
+
+// Ensure Tracer implements memu.Tracer interface
+var _ memu.Tracer = (*Tracer)(nil)
+
+// New creates a memu.Tracer that records spans with tracer.
+func New(tracer trace.Tracer) *Tracer {
+	return &Tracer{tracer: tracer}
+}
+
+// WithTracing returns a client option that wraps each API call in a client
+// span named after the method (e.g. "Retrieve"). Spans are children of the
+// span in the caller's context, if any, and carry the memu.WithName label as
+// the memu.client.name attribute.
+func WithTracing(tracer trace.Tracer) memu.Option {
+	return memu.WithTracer(New(tracer))
+}
+
+// StartOperation implements memu.Tracer.
+func (t *Tracer) StartOperation(ctx context.Context, operation string) (context.Context, func(statusCode int, err error)) {
+	ctx, span := t.tracer.Start(ctx, operation, trace.WithSpanKind(trace.SpanKindClient))
+	if name := memu.ClientName(ctx); name != "" {
+		span.SetAttributes(clientNameKey.String(name))
+	}
+	return ctx, func(statusCode int, err error) {
+		if statusCode != 0 {
+			span.SetAttributes(statusCodeKey.Int(statusCode))
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
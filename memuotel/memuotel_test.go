@@ -0,0 +1,141 @@
+package memuotel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	memu "github.com/NevaMind-AI/memU-sdk-go"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newRecordingProvider() (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), recorder
+}
+
+func statusCodeOf(span sdktrace.ReadOnlySpan) (int64, bool) {
+	for _, attr := range span.Attributes() {
+		if attr.Key == statusCodeKey {
+			return attr.Value.AsInt64(), true
+		}
+	}
+	return 0, false
+}
+
+func TestWithTracing_OneSpanPerCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/memory/memorize/status/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"task not found"}`))
+			return
+		}
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	provider, recorder := newRecordingProvider()
+	client, err := memu.NewClient("test_key", memu.WithBaseURL(server.URL), WithTracing(provider.Tracer("memu-test")))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	if _, err := client.GetTaskStatus(context.Background(), "t1"); err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+	if _, err := client.GetTaskStatus(context.Background(), "missing"); err == nil {
+		t.Fatal("expected error for missing task, got nil")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	for _, span := range spans {
+		if span.Name() != "GetTaskStatus" {
+			t.Errorf("expected span name GetTaskStatus, got %q", span.Name())
+		}
+		if span.SpanKind() != trace.SpanKindClient {
+			t.Errorf("expected client span, got %v", span.SpanKind())
+		}
+	}
+
+	if code, ok := statusCodeOf(spans[0]); !ok || code != 200 {
+		t.Errorf("expected status code 200, got %d (set: %v)", code, ok)
+	}
+	if spans[0].Status().Code != codes.Unset {
+		t.Errorf("expected unset status on success, got %v", spans[0].Status())
+	}
+	if code, ok := statusCodeOf(spans[1]); !ok || code != 404 {
+		t.Errorf("expected status code 404, got %d (set: %v)", code, ok)
+	}
+	if spans[1].Status().Code != codes.Error {
+		t.Errorf("expected error status, got %v", spans[1].Status())
+	}
+	if len(spans[1].Events()) != 1 || spans[1].Events()[0].Name != "exception" {
+		t.Errorf("expected a recorded error event, got %v", spans[1].Events())
+	}
+}
+
+func TestWithTracing_NestsUnderCallerSpan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	provider, recorder := newRecordingProvider()
+	tracer := provider.Tracer("memu-test")
+	client, _ := memu.NewClient("test_key", memu.WithBaseURL(server.URL), WithTracing(tracer))
+
+	ctx, parent := tracer.Start(context.Background(), "handler")
+	if _, err := client.GetTaskStatus(ctx, "t1"); err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	child := spans[0]
+	if child.Name() != "GetTaskStatus" {
+		t.Fatalf("expected the SDK span to end first, got %q", child.Name())
+	}
+	if child.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("expected the SDK span to be a child of the caller's span")
+	}
+	if child.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+		t.Error("expected the SDK span to share the caller's trace")
+	}
+}
+
+func TestWithTracing_ClientNameAttribute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	provider, recorder := newRecordingProvider()
+	client, _ := memu.NewClient("test_key", memu.WithBaseURL(server.URL), memu.WithName("tenant-a"), WithTracing(provider.Tracer("memu-test")))
+	if _, err := client.GetTaskStatus(context.Background(), "t1"); err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	found := false
+	for _, attr := range spans[0].Attributes() {
+		if attr.Key == clientNameKey && attr.Value.AsString() == "tenant-a" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected memu.client.name attribute tenant-a, got %v", spans[0].Attributes())
+	}
+}
@@ -0,0 +1,131 @@
+// Package memuprom provides Prometheus metrics for the MemU SDK.
+// It is a separate module so the core memu module stays free of the
+// Prometheus dependency.
+package memuprom
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	memu "github.com/NevaMind-AI/memU-sdk-go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics implements memu.Metrics with Prometheus collectors.
+type Metrics struct {
+	// requests counts API calls by client name, method and status code.
+	requests *prometheus.CounterVec
+	// errors counts failed API calls by client name, method and status code.
+	errors *prometheus.CounterVec
+	// latency observes API call durations in seconds by client name and method.
+	latency *prometheus.HistogramVec
+	// retries counts retried attempts by client name, method, cause and status code.
+	retries *prometheus.CounterVec
+}
+
+// Ensure Metrics implements memu.NamedMetrics interface
+var _ memu.NamedMetrics = (*Metrics)(nil)
+
+// New creates the MemU collectors and registers them with reg.
+func New(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "memu_requests_total",
+			Help: "Total number of MemU API calls.",
+		}, []string{"client", "method", "status"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "memu_request_errors_total",
+			Help: "Total number of failed MemU API calls.",
+		}, []string{"client", "method", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "memu_request_duration_seconds",
+			Help:    "Duration of MemU API calls including retries.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"client", "method"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "memu_retries_total",
+			Help: "Total number of retried MemU API attempts.",
+		}, []string{"client", "method", "cause", "status"}),
+	}
+
+	// Reuse the collectors of an earlier New call on the same registry, so
+	// that several clients can share it, told apart by memu.WithName
+	var err error
+	if m.requests, err = register(reg, m.requests); err != nil {
+		return nil, err
+	}
+	if m.errors, err = register(reg, m.errors); err != nil {
+		return nil, err
+	}
+	if m.latency, err = register(reg, m.latency); err != nil {
+		return nil, err
+	}
+	if m.retries, err = register(reg, m.retries); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// register registers collector with reg and returns it, or the collector
+// already registered with the same descriptor if it has the same type.
+func register[C prometheus.Collector](reg prometheus.Registerer, collector C) (C, error) {
+	err := reg.Register(collector)
+	if err == nil {
+		return collector, nil
+	}
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		if existing, ok := registered.ExistingCollector.(C); ok {
+			return existing, nil
+		}
+	}
+	return collector, err
+}
+
+// WithPrometheus returns a client option that records metrics into reg.
+// Several clients may use the same registry; their collectors are shared and
+// the client label, set with memu.WithName, tells them apart. It panics only
+// if the collectors conflict with different metrics registered under the same
+// names; use New and memu.WithMetrics to handle that error.
+func WithPrometheus(reg *prometheus.Registry) memu.Option {
+	m, err := New(reg)
+	if err != nil {
+		panic(err)
+	}
+	return memu.WithMetrics(m)
+}
+
+// ObserveRequest implements memu.Metrics, recording an empty client label.
+func (m *Metrics) ObserveRequest(method string, statusCode int, duration time.Duration, err error) {
+	m.ObserveNamedRequest("", method, statusCode, duration, err)
+}
+
+// ObserveRetry implements memu.Metrics, recording an empty client label.
+func (m *Metrics) ObserveRetry(method string, cause memu.RetryCause, statusCode int, err error) {
+	m.ObserveNamedRetry("", method, cause, statusCode, err)
+}
+
+// ObserveNamedRequest implements memu.NamedMetrics.
+func (m *Metrics) ObserveNamedRequest(client, method string, statusCode int, duration time.Duration, err error) {
+	status := statusLabel(statusCode)
+	m.requests.WithLabelValues(client, method, status).Inc()
+	m.latency.WithLabelValues(client, method).Observe(duration.Seconds())
+	if err != nil {
+		m.errors.WithLabelValues(client, method, status).Inc()
+	}
+}
+
+// ObserveNamedRetry implements memu.NamedMetrics.
+func (m *Metrics) ObserveNamedRetry(client, method string, cause memu.RetryCause, statusCode int, err error) {
+	m.retries.WithLabelValues(client, method, string(cause), statusLabel(statusCode)).Inc()
+}
+
+// statusLabel converts a status code to a label value, using "none" when no
+// response was received.
+func statusLabel(statusCode int) string {
+	if statusCode == 0 {
+		return "none"
+	}
+	return strconv.Itoa(statusCode)
+}
@@ -0,0 +1,178 @@
+// Package memuprom provides unit tests for the Prometheus metrics.
+// This file validates that collectors are updated by client calls.
+package memuprom
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	memu "github.com/NevaMind-AI/memU-sdk-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics_CountsRequests(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v3/memory/memorize/status/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case atomic.AddInt32(&requests, 1) == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+		}
+	}))
+	defer server.Close()
+
+	m, err := New(prometheus.NewRegistry())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	retryPolicy := memu.NewCustomRetryPolicy(3,
+		func(attempt int, statusCode int, err error) bool { return true },
+		func(attempt int) time.Duration { return 0 },
+	)
+	client, err := memu.NewClient("test_key",
+		memu.WithBaseURL(server.URL),
+		memu.WithRetryPolicy(retryPolicy),
+		memu.WithMetrics(m),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := client.GetTaskStatus(ctx, "t1"); err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+	if _, err := client.GetTaskStatus(ctx, "missing"); err == nil {
+		t.Fatal("expected error for missing task, got nil")
+	}
+
+	if got := testutil.ToFloat64(m.requests.WithLabelValues("", "GetTaskStatus", "200")); got != 1 {
+		t.Errorf("expected 1 successful request, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.requests.WithLabelValues("", "GetTaskStatus", "404")); got != 1 {
+		t.Errorf("expected 1 not-found request, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.errors.WithLabelValues("", "GetTaskStatus", "404")); got != 1 {
+		t.Errorf("expected 1 not-found error, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.retries.WithLabelValues("", "GetTaskStatus", "status_5xx", "503")); got != 1 {
+		t.Errorf("expected 1 retry after 503, got %v", got)
+	}
+	if got := testutil.CollectAndCount(m.latency); got != 1 {
+		t.Errorf("expected 1 latency series, got %d", got)
+	}
+}
+
+func TestNew_SharedRegistry(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	first, err := New(reg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	second, err := New(reg)
+	if err != nil {
+		t.Fatalf("expected the collectors to be reused, got %v", err)
+	}
+	if first.requests != second.requests || first.latency != second.latency {
+		t.Error("expected both Metrics to share the registered collectors")
+	}
+}
+
+func TestNew_ConflictingRegistration(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Name: "memu_requests_total", Help: "Unrelated."}))
+	if _, err := New(reg); err == nil {
+		t.Fatal("expected error for a conflicting collector, got nil")
+	}
+}
+
+func TestWithPrometheus_RegistersCollectors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	reg := prometheus.NewRegistry()
+	client, err := memu.NewClient("test_key", memu.WithBaseURL(server.URL), WithPrometheus(reg))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err := client.GetTaskStatus(context.Background(), "t1"); err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+
+	count, err := testutil.GatherAndCount(reg, "memu_requests_total", "memu_request_duration_seconds")
+	if err != nil {
+		t.Fatalf("GatherAndCount failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 series, got %d", count)
+	}
+}
+
+func TestMetrics_ClientNameLabel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	m, err := New(prometheus.NewRegistry())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	// One Metrics shared by two named clients
+	for _, name := range []string{"tenant-a", "tenant-b"} {
+		client, _ := memu.NewClient("test_key", memu.WithBaseURL(server.URL), memu.WithMetrics(m), memu.WithName(name))
+		if _, err := client.GetTaskStatus(context.Background(), "t1"); err != nil {
+			t.Fatalf("GetTaskStatus failed: %v", err)
+		}
+	}
+
+	for _, name := range []string{"tenant-a", "tenant-b"} {
+		if got := testutil.ToFloat64(m.requests.WithLabelValues(name, "GetTaskStatus", "200")); got != 1 {
+			t.Errorf("expected 1 request labeled %s, got %v", name, got)
+		}
+	}
+}
+
+func TestWithPrometheus_SharedRegistry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING","items":[]}`))
+	}))
+	defer server.Close()
+
+	text := "user: I like tea"
+	reg := prometheus.NewRegistry()
+	for _, name := range []string{"tenant-a", "tenant-b"} {
+		client, err := memu.NewClient("test_key", memu.WithBaseURL(server.URL), WithPrometheus(reg), memu.WithName(name))
+		if err != nil {
+			t.Fatalf("NewClient failed: %v", err)
+		}
+		if _, err := client.Memorize(context.Background(), &memu.MemorizeRequest{
+			UserID: "user_123", AgentID: "agent_456", ConversationText: &text,
+		}); err != nil {
+			t.Fatalf("Memorize failed: %v", err)
+		}
+		if _, err := client.Retrieve(context.Background(), memu.NewStringRetrieve("user_123", "agent_456", "tea")); err != nil {
+			t.Fatalf("Retrieve failed: %v", err)
+		}
+	}
+
+	m, _ := New(reg)
+	for _, name := range []string{"tenant-a", "tenant-b"} {
+		// Both calls are POSTs but are counted per operation
+		for _, operation := range []string{"Memorize", "Retrieve"} {
+			if got := testutil.ToFloat64(m.requests.WithLabelValues(name, operation, "200")); got != 1 {
+				t.Errorf("expected 1 %s request labeled %s, got %v", operation, name, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Package memu provides the metrics hook for the MemU SDK.
+// This file defines the Metrics interface used to observe requests and retries.
+package memu
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// RetryCause describes why a request attempt was retried.
+type RetryCause string
+
+const (
+	// RetryCauseNetwork indicates a transport error other than a timeout.
+	RetryCauseNetwork RetryCause = "network"
+	// RetryCauseTimeout indicates a transport-level timeout.
+	RetryCauseTimeout RetryCause = "timeout"
+	// RetryCauseStatus429 indicates a 429 Too Many Requests response.
+	RetryCauseStatus429 RetryCause = "status_429"
+	// RetryCauseStatus5xx indicates a 5xx server error response.
+	RetryCauseStatus5xx RetryCause = "status_5xx"
+	// RetryCauseTransientBody indicates a successful response whose body was
+	// reported as a transient failure by the WithBodyRetryPredicate predicate.
+	RetryCauseTransientBody RetryCause = "transient_body"
+	// RetryCauseAuthRefresh indicates a 401 response followed by a token refresh.
+	RetryCauseAuthRefresh RetryCause = "auth_refresh"
+	// RetryCauseErrorMessage indicates a 4xx response whose message matched
+	// WithRetryableErrorMessages.
+	RetryCauseErrorMessage RetryCause = "error_message"
+)
+
+// Metrics receives observations about API requests made by the client. The
+// method argument is the SDK operation, e.g. "Memorize" or "GetTaskStatus",
+// not the HTTP verb, so that operations sharing a verb stay distinguishable.
+// Implementations must be safe for concurrent use.
+// See the memuprom subpackage for a Prometheus implementation.
+type Metrics interface {
+	// ObserveRequest is called once per API call after all retries complete.
+	// statusCode is the last HTTP status received, or 0 if no response was received.
+	ObserveRequest(method string, statusCode int, duration time.Duration, err error)
+
+	// ObserveRetry is called each time a request attempt is retried.
+	// cause tells which retry branch was taken; statusCode is 0 when the
+	// attempt failed with a transport error.
+	ObserveRetry(method string, cause RetryCause, statusCode int, err error)
+}
+
+// NamedMetrics is implemented by Metrics that label observations with the
+// client name set by WithName, so that one implementation can be shared by
+// several clients. The client calls the named methods instead of
+// ObserveRequest and ObserveRetry when its Metrics implement them.
+type NamedMetrics interface {
+	Metrics
+
+	// ObserveNamedRequest is ObserveRequest with the client name ("" if unset).
+	ObserveNamedRequest(client, method string, statusCode int, duration time.Duration, err error)
+
+	// ObserveNamedRetry is ObserveRetry with the client name ("" if unset).
+	ObserveNamedRetry(client, method string, cause RetryCause, statusCode int, err error)
+}
+
+// observeRequest reports a completed API call to the configured Metrics, if any.
+func (c *Client) observeRequest(method string, statusCode int, duration time.Duration, err error) {
+	if named, ok := c.metrics.(NamedMetrics); ok {
+		named.ObserveNamedRequest(c.name, method, statusCode, duration, err)
+	} else if c.metrics != nil {
+		c.metrics.ObserveRequest(method, statusCode, duration, err)
+	}
+}
+
+// observeRetry reports a retry to the configured Metrics, if any.
+func (c *Client) observeRetry(method string, cause RetryCause, statusCode int, err error) {
+	if named, ok := c.metrics.(NamedMetrics); ok {
+		named.ObserveNamedRetry(c.name, method, cause, statusCode, err)
+	} else if c.metrics != nil {
+		c.metrics.ObserveRetry(method, cause, statusCode, err)
+	}
+}
+
+// transportRetryCause classifies a transport error as a timeout or other
+// network failure.
+func transportRetryCause(err error) RetryCause {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return RetryCauseTimeout
+	}
+	return RetryCauseNetwork
+}
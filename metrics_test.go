@@ -0,0 +1,141 @@
+// Package memu provides unit tests for the metrics hook.
+// This file validates the retry causes reported to Metrics.
+package memu
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordingMetrics records the retry causes it observes.
+type recordingMetrics struct {
+	mu     sync.Mutex
+	causes []RetryCause
+}
+
+func (m *recordingMetrics) ObserveRequest(method string, statusCode int, duration time.Duration, err error) {
+}
+
+func (m *recordingMetrics) ObserveRetry(method string, cause RetryCause, statusCode int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.causes = append(m.causes, cause)
+}
+
+// newFlakyServer returns a server that answers the first request with the
+// given status and every later request with an empty JSON object.
+func newFlakyServer(status int, body string) *httptest.Server {
+	var requests int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+			return
+		}
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+}
+
+func TestMetrics_RetryCauses(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	var slowRequests int32
+	slow := newSlowServer(200*time.Millisecond, &slowRequests)
+	defer slow.Close()
+
+	tests := []struct {
+		name     string
+		baseURL  func() (string, func())
+		opts     []Option
+		expected RetryCause
+	}{
+		{
+			name: "network",
+			baseURL: func() (string, func()) {
+				return closed.URL, func() {}
+			},
+			expected: RetryCauseNetwork,
+		},
+		{
+			name: "timeout",
+			baseURL: func() (string, func()) {
+				return slow.URL, func() {}
+			},
+			opts:     []Option{WithTimeout(20 * time.Millisecond)},
+			expected: RetryCauseTimeout,
+		},
+		{
+			name: "status 429",
+			baseURL: func() (string, func()) {
+				server := newFlakyServer(http.StatusTooManyRequests, `{}`)
+				return server.URL, server.Close
+			},
+			expected: RetryCauseStatus429,
+		},
+		{
+			name: "status 5xx",
+			baseURL: func() (string, func()) {
+				server := newFlakyServer(http.StatusBadGateway, `{}`)
+				return server.URL, server.Close
+			},
+			expected: RetryCauseStatus5xx,
+		},
+		{
+			name: "transient body",
+			baseURL: func() (string, func()) {
+				server := newFlakyServer(http.StatusOK, `{"status":"retry_later"}`)
+				return server.URL, server.Close
+			},
+			opts: []Option{WithBodyRetryPredicate(func(body map[string]interface{}) bool {
+				return body["status"] == "retry_later"
+			})},
+			expected: RetryCauseTransientBody,
+		},
+		{
+			name: "error message",
+			baseURL: func() (string, func()) {
+				server := newFlakyServer(http.StatusBadRequest, `{"message":"Temporarily unavailable, retry"}`)
+				return server.URL, server.Close
+			},
+			opts:     []Option{WithRetryableErrorMessages("temporarily unavailable")},
+			expected: RetryCauseErrorMessage,
+		},
+		{
+			name: "auth refresh",
+			baseURL: func() (string, func()) {
+				server := newFlakyServer(http.StatusUnauthorized, `{}`)
+				return server.URL, server.Close
+			},
+			opts: []Option{WithTokenRefresh(func(ctx context.Context) (string, error) {
+				return "new_key", nil
+			})},
+			expected: RetryCauseAuthRefresh,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseURL, cleanup := tt.baseURL()
+			defer cleanup()
+
+			metrics := &recordingMetrics{}
+			opts := append([]Option{
+				WithBaseURL(baseURL),
+				WithRetryPolicy(zeroBackoffPolicy(1)),
+				WithMetrics(metrics),
+			}, tt.opts...)
+			client, _ := NewClient("test_key", opts...)
+			client.GetTaskStatus(context.Background(), "t1")
+
+			if len(metrics.causes) == 0 || metrics.causes[0] != tt.expected {
+				t.Errorf("expected first retry cause %q, got %v", tt.expected, metrics.causes)
+			}
+		})
+	}
+}
@@ -3,7 +3,17 @@
 package memu
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Validator defines the parameter validation interface.
@@ -26,6 +36,30 @@ const (
 	TaskStatusSuccess TaskStatusEnum = "SUCCESS"
 	// TaskStatusFailed indicates the task failed.
 	TaskStatusFailed TaskStatusEnum = "FAILED"
+	// TaskStatusCancelled indicates the task was cancelled before finishing.
+	TaskStatusCancelled TaskStatusEnum = "CANCELLED"
+)
+
+// IsTerminal reports whether the status is final (COMPLETED, SUCCESS, FAILED
+// or CANCELLED).
+func (s TaskStatusEnum) IsTerminal() bool {
+	switch s {
+	case TaskStatusCompleted, TaskStatusSuccess, TaskStatusFailed, TaskStatusCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// MemorizeMode selects how the server processes a memorization request.
+type MemorizeMode string
+
+const (
+	// MemorizeModeAsync queues the memorization and returns a task ID (default).
+	MemorizeModeAsync MemorizeMode = "async"
+	// MemorizeModeSync performs the memorization inline and returns extracted items.
+	// Sync mode may time out for large inputs.
+	MemorizeModeSync MemorizeMode = "sync"
 )
 
 // MemoryResource represents a raw resource stored in MemU.
@@ -48,10 +82,19 @@ type MemoryResource struct {
 // Memory items are individual pieces of information such as preferences,
 // skills, opinions, habits, relationships, etc.
 type MemoryItem struct {
+	// ID is the unique identifier of the memory item.
+	ID *string `json:"id,omitempty"`
 	// Content is the textual content of the memory item.
 	Content *string `json:"content,omitempty"`
 	// MemoryType categorizes the type of memory (e.g., "preference", "skill", "fact").
 	MemoryType *string `json:"memory_type,omitempty"`
+	// Sources holds the source text snippets the item was derived from.
+	// Populated only when the retrieval requested IncludeSources.
+	Sources []string `json:"sources,omitempty"`
+	// CreatedAt is the timestamp at which the item was created.
+	CreatedAt *string `json:"created_at,omitempty"`
+	// RawExtra contains response fields not yet modeled by the SDK.
+	RawExtra map[string]interface{} `json:"-"`
 }
 
 // MemoryCategory represents an aggregated memory category.
@@ -68,6 +111,11 @@ type MemoryCategory struct {
 	UserID *string `json:"user_id,omitempty"`
 	// AgentID is the agent ID this category is associated with.
 	AgentID *string `json:"agent_id,omitempty"`
+	// Items contains the memory items of this category, when the response
+	// nests them under the category.
+	Items []*MemoryItem `json:"items,omitempty"`
+	// RawExtra contains response fields not yet modeled by the SDK.
+	RawExtra map[string]interface{} `json:"-"`
 }
 
 // TaskStatus represents status information for an asynchronous memorization task.
@@ -80,6 +128,8 @@ type TaskStatus struct {
 	Message string `json:"message,omitempty"`
 	// DetailInfo contains additional detailed information about the task.
 	DetailInfo string `json:"detail_info,omitempty"`
+	// RawExtra contains response fields not yet modeled by the SDK.
+	RawExtra map[string]interface{} `json:"-"`
 }
 
 // RetrieveResult represents the result of a memory retrieval operation.
@@ -87,16 +137,202 @@ type RetrieveResult struct {
 	// RewrittenQuery is the query after being rewritten by the system for better retrieval.
 	RewrittenQuery *string `json:"rewritten_query,omitempty"`
 	// Categories contains the retrieved memory categories.
-	Categories []*MemoryCategory `json:"categories,omitempty"`
+	Categories []*MemoryCategory `json:"categories"`
 	// Items contains the retrieved memory items.
-	Items []*MemoryItem `json:"items,omitempty"`
+	Items []*MemoryItem `json:"items"`
 	// Resources contains the retrieved memory resources.
-	Resources []*MemoryResource `json:"resources,omitempty"`
+	Resources []*MemoryResource `json:"resources"`
+	// TotalCount is the total number of matching items across all pages, if
+	// reported by the API.
+	TotalCount *int `json:"total_count,omitempty"`
+	// Warnings contains non-fatal issues reported by the server.
+	Warnings []string `json:"warnings,omitempty"`
+	// DroppedElements is the number of categories, items and resources skipped
+	// because they could not be parsed. It is only non-zero with WithRetrieveLenient.
+	DroppedElements int `json:"-"`
+	// Fallback reports whether the results come from the WithRetrieveFallback
+	// query because the primary query returned no items.
+	Fallback bool `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, collecting unknown fields into RawExtra.
+func (m *MemoryItem) UnmarshalJSON(data []byte) error {
+	type alias MemoryItem
+	var a alias
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	extra, err := unknownFields(data, a)
+	if err != nil {
+		return err
+	}
+	*m = MemoryItem(a)
+	m.RawExtra = extra
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, collecting unknown fields into RawExtra.
+func (m *MemoryCategory) UnmarshalJSON(data []byte) error {
+	type alias MemoryCategory
+	var a alias
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	extra, err := unknownFields(data, a)
+	if err != nil {
+		return err
+	}
+	*m = MemoryCategory(a)
+	m.RawExtra = extra
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, collecting unknown fields into RawExtra.
+func (s *TaskStatus) UnmarshalJSON(data []byte) error {
+	type alias TaskStatus
+	var a alias
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	extra, err := unknownFields(data, a)
+	if err != nil {
+		return err
+	}
+	*s = TaskStatus(a)
+	s.RawExtra = extra
+	return nil
+}
+
+// unknownFields returns the keys of a JSON object that are not mapped to a
+// json-tagged field of model's struct type. It returns nil when there are none.
+func unknownFields(data []byte, model interface{}) (map[string]interface{}, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	t := reflect.TypeOf(model)
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			delete(fields, name)
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+// Preview returns Content truncated to at most n runes, with "..." appended
+// if it was cut. It returns "" if Content is nil.
+func (m *MemoryItem) Preview(n int) string {
+	return truncateRunes(m.Content, n)
+}
+
+// SummaryPreview returns Summary truncated to at most n runes, with "..."
+// appended if it was cut. It returns "" if Summary is nil.
+func (m *MemoryCategory) SummaryPreview(n int) string {
+	return truncateRunes(m.Summary, n)
+}
+
+// truncateRunes returns *s cut to at most n runes on a rune boundary, with
+// "..." appended if it was cut, or "" if s is nil.
+func truncateRunes(s *string, n int) string {
+	if s == nil {
+		return ""
+	}
+	if n < 0 {
+		n = 0
+	}
+	if utf8.RuneCountInString(*s) <= n {
+		return *s
+	}
+	runes := []rune(*s)
+	return string(runes[:n]) + "..."
+}
+
+// GetRewrittenQuery returns the rewritten query, or an empty string if the
+// server did not return one.
+func (r *RetrieveResult) GetRewrittenQuery() string {
+	if r == nil || r.RewrittenQuery == nil {
+		return ""
+	}
+	return *r.RewrittenQuery
+}
+
+// WasRewritten reports whether the server rewrote the original query.
+func (r *RetrieveResult) WasRewritten(original string) bool {
+	if r == nil || r.RewrittenQuery == nil {
+		return false
+	}
+	return *r.RewrittenQuery != original
+}
+
+// Normalize makes the emptiness of the result slices uniform so that the
+// result always serializes to the same JSON shape. By default empty slices are
+// used, which marshal to []; with nilSlices set, empty slices are replaced by
+// nil, which marshal to null. It returns r for chaining.
+func (r *RetrieveResult) Normalize(nilSlices bool) *RetrieveResult {
+	if r == nil {
+		return nil
+	}
+	if nilSlices {
+		if len(r.Categories) == 0 {
+			r.Categories = nil
+		}
+		if len(r.Items) == 0 {
+			r.Items = nil
+		}
+		if len(r.Resources) == 0 {
+			r.Resources = nil
+		}
+		return r
+	}
+	if r.Categories == nil {
+		r.Categories = []*MemoryCategory{}
+	}
+	if r.Items == nil {
+		r.Items = []*MemoryItem{}
+	}
+	if r.Resources == nil {
+		r.Resources = []*MemoryResource{}
+	}
+	return r
+}
+
+// Conversation message roles.
+const (
+	// RoleUser is the role of messages sent by the user.
+	RoleUser = "user"
+	// RoleAssistant is the role of messages sent by the assistant.
+	RoleAssistant = "assistant"
+	// RoleSystem is the role of system instructions.
+	RoleSystem = "system"
+	// RoleTool is the role of tool call results.
+	RoleTool = "tool"
+	// RoleFunction is the role of legacy function call results.
+	RoleFunction = "function"
+)
+
+// validRoles is the set of roles accepted in conversation messages.
+var validRoles = map[string]bool{
+	RoleUser:      true,
+	RoleAssistant: true,
+	RoleSystem:    true,
+	RoleTool:      true,
+	RoleFunction:  true,
+}
+
+// IsValidRole reports whether role is an accepted conversation message role.
+func IsValidRole(role string) bool {
+	return validRoles[role]
 }
 
 // ConversationMessage represents a single message in a conversation.
 type ConversationMessage struct {
-	// Role is the role of the message sender (e.g., "user", "assistant", "system").
+	// Role is the role of the message sender (e.g., "user", "assistant", "system", "tool").
 	Role string `json:"role"`
 	// Content is the textual content of the message.
 	Content string `json:"content"`
@@ -104,6 +340,32 @@ type ConversationMessage struct {
 	Name *string `json:"name,omitempty"`
 	// CreatedAt is an optional timestamp for when the message was created.
 	CreatedAt *string `json:"created_at,omitempty"`
+	// ToolCallID is the ID of the tool call this message responds to (tool role only).
+	ToolCallID *string `json:"tool_call_id,omitempty"`
+	// ToolName is the name of the tool or function that produced this message.
+	ToolName *string `json:"tool_name,omitempty"`
+}
+
+// Validate checks that the message has a supported role, non-empty content
+// and, if set, a CreatedAt timestamp in ISO 8601 or Unix epoch format.
+func (m ConversationMessage) Validate() error {
+	return m.validateWithTimeFormat("")
+}
+
+// validateWithTimeFormat is Validate that also accepts CreatedAt in layout.
+func (m ConversationMessage) validateWithTimeFormat(layout string) error {
+	if !IsValidRole(m.Role) {
+		return fmt.Errorf("ConversationMessage: Role %q is not supported", m.Role)
+	}
+	if strings.TrimSpace(m.Content) == "" {
+		return fmt.Errorf("ConversationMessage: Content is required")
+	}
+	if m.CreatedAt != nil {
+		if _, ok := parseTimestamp(*m.CreatedAt, layout); !ok {
+			return fmt.Errorf("ConversationMessage: CreatedAt %q is not a valid timestamp", *m.CreatedAt)
+		}
+	}
+	return nil
 }
 
 // MemorizeRequest represents a request to memorize a conversation.
@@ -122,11 +384,22 @@ type MemorizeRequest struct {
 	AgentName string `json:"agent_name,omitempty"`
 	// SessionDate is an optional session date in ISO format.
 	SessionDate *string `json:"session_date,omitempty"`
+	// Mode selects async (default) or sync memorization.
+	// Sync mode may time out for large inputs; prefer async for long conversations.
+	Mode MemorizeMode `json:"mode,omitempty"`
+	// Language is an optional BCP 47 language tag (e.g. "en", "zh-Hans")
+	// hinting the language of the conversation to improve extraction.
+	Language *string `json:"language,omitempty"`
+	// IdempotencyKey is sent as the Idempotency-Key header so the server can
+	// deduplicate replays. Setting it allows Memorize to be retried after
+	// ambiguous network errors.
+	IdempotencyKey *string `json:"-"`
 }
 
 // MemorizeResult represents the result of a memorization operation.
-// The API returns only task_id, status, and message.
-// To get the extracted memories, use GetTaskStatus or Retrieve API.
+// In async mode the API returns only task_id, status, and message; use
+// GetTaskStatus or Retrieve API to get the extracted memories.
+// In sync mode the extracted items are returned in Items.
 type MemorizeResult struct {
 	// TaskID is the unique identifier for the memorization task.
 	TaskID *string `json:"task_id,omitempty"`
@@ -134,6 +407,18 @@ type MemorizeResult struct {
 	Status *string `json:"status,omitempty"`
 	// Message provides a human-readable message about the task.
 	Message *string `json:"message,omitempty"`
+	// Items contains the extracted memory items (sync mode only).
+	Items []*MemoryItem `json:"items,omitempty"`
+	// Categories contains the memory categories affected by the memorization (sync mode only).
+	Categories []*MemoryCategory `json:"categories,omitempty"`
+	// Warnings contains non-fatal issues reported by the server, e.g. "truncated conversation".
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// IsEmpty reports whether the result carries no task ID, status, message,
+// items or categories, as happens when the API returns an empty success body.
+func (r *MemorizeResult) IsEmpty() bool {
+	return r.TaskID == nil && r.Status == nil && r.Message == nil && len(r.Items) == 0 && len(r.Categories) == 0
 }
 
 // RetrieveRequest represents a request to retrieve memories.
@@ -144,6 +429,42 @@ type RetrieveRequest struct {
 	UserID string `json:"user_id"`
 	// AgentID is the agent ID for scoping (required).
 	AgentID string `json:"agent_id"`
+	// TypeWeights optionally biases ranking by memory type (e.g., {"preference": 2.0}).
+	TypeWeights map[string]float64 `json:"type_weights,omitempty"`
+	// CategoriesOnly requests a trimmed response containing only categories.
+	// When true, Items and Resources of the result are always empty.
+	CategoriesOnly *bool `json:"categories_only,omitempty"`
+	// IncludeSources asks the API to attach the source snippets each item
+	// was derived from to MemoryItem.Sources.
+	IncludeSources *bool `json:"include_sources,omitempty"`
+	// Offset skips this many results, for simple offset-based paging.
+	Offset *int `json:"offset,omitempty"`
+	// Limit caps the number of results returned.
+	Limit *int `json:"limit,omitempty"`
+	// RecencyBoost weights recent items higher, from 0 (no boost) to 1
+	// (strongest). It requires backend support and is ignored otherwise.
+	RecencyBoost *float64 `json:"recency_boost,omitempty"`
+	// ExtraParams are sent as query-string parameters, e.g. to enable
+	// experimental backend features. Keys and values must be non-empty.
+	ExtraParams map[string]string `json:"-"`
+}
+
+// NewStringRetrieve creates a RetrieveRequest with a plain string query.
+func NewStringRetrieve(userID, agentID, query string) *RetrieveRequest {
+	return &RetrieveRequest{
+		Query:   query,
+		UserID:  userID,
+		AgentID: agentID,
+	}
+}
+
+// NewConversationRetrieve creates a RetrieveRequest with a conversation query.
+func NewConversationRetrieve(userID, agentID string, msgs []ConversationMessage) *RetrieveRequest {
+	return &RetrieveRequest{
+		Query:   msgs,
+		UserID:  userID,
+		AgentID: agentID,
+	}
 }
 
 // ListCategoriesRequest represents a request to list memory categories.
@@ -151,28 +472,278 @@ type ListCategoriesRequest struct {
 	// UserID is the user ID for scoping (required).
 	UserID string `json:"user_id"`
 	// AgentID is the agent ID for scoping (optional).
+	// When nil, categories across all agents of the user are listed.
 	AgentID *string `json:"agent_id,omitempty"`
 }
 
+// ReprocessRequest represents a request to re-run memory extraction on an
+// already stored resource. Either ResourceID or ResourceURL must be provided.
+type ReprocessRequest struct {
+	// UserID is the user ID for scoping (required).
+	UserID string `json:"user_id"`
+	// AgentID is the agent ID for scoping (required).
+	AgentID string `json:"agent_id"`
+	// ResourceID is the ID of the stored resource.
+	ResourceID string `json:"resource_id,omitempty"`
+	// ResourceURL is the URL of the stored resource.
+	ResourceURL string `json:"resource_url,omitempty"`
+}
+
+// GetMemoryItemRequest represents a request to fetch a single memory item.
+type GetMemoryItemRequest struct {
+	// UserID is the user ID for scoping (required).
+	UserID string `json:"user_id"`
+	// ID is the memory item ID (required).
+	ID string `json:"id"`
+}
+
+// RetrieveSimilarRequest represents a request to retrieve memories similar to
+// a seed. Either ItemID or Content must be provided.
+type RetrieveSimilarRequest struct {
+	// UserID is the user ID for scoping (required).
+	UserID string `json:"user_id"`
+	// AgentID is the agent ID for scoping (required).
+	AgentID string `json:"agent_id"`
+	// ItemID is the ID of a stored memory item to use as the seed.
+	ItemID string `json:"item_id,omitempty"`
+	// Content is free text to use as the seed, e.g. the content of an item
+	// that has not been stored.
+	Content string `json:"content,omitempty"`
+}
+
+// RecentMemoriesRequest represents a request for the most recently created
+// memory items of a user and agent.
+type RecentMemoriesRequest struct {
+	// UserID is the user ID for scoping (required).
+	UserID string `json:"user_id"`
+	// AgentID is the agent ID for scoping (required).
+	AgentID string `json:"agent_id"`
+	// Limit is the maximum number of items to return. Zero uses the server default.
+	Limit int `json:"limit,omitempty"`
+}
+
+// ReassignRequest represents a request to move a user's memories from one
+// agent to another.
+type ReassignRequest struct {
+	// UserID is the user whose memories are moved (required).
+	UserID string `json:"user_id"`
+	// FromAgentID is the agent the memories currently belong to (required).
+	FromAgentID string `json:"from_agent_id"`
+	// ToAgentID is the agent the memories are moved to (required).
+	ToAgentID string `json:"to_agent_id"`
+}
+
+// ReassignResult represents the result of a ReassignMemories operation.
+type ReassignResult struct {
+	// MovedCount is the number of memory items moved.
+	MovedCount int `json:"moved_count"`
+}
+
+// minConversationMessages is the minimum number of messages in a Memorize
+// conversation.
+const minConversationMessages = 3
+
+// tooFewMessagesMessage is reported for conversations shorter than
+// minConversationMessages, both by Validate and for matching server rejections.
+var tooFewMessagesMessage = fmt.Sprintf("Memorize: Conversation must contain at least %d messages", minConversationMessages)
+
+// languageTagPattern matches the basic shape of a BCP 47 language tag: a
+// primary language subtag followed by optional hyphen-separated subtags.
+var languageTagPattern = regexp.MustCompile(`^[A-Za-z]{2,8}(-[A-Za-z0-9]{1,8})*$`)
+
 // Validate validates MemorizeRequest parameters.
 func (r *MemorizeRequest) Validate() error {
+	return r.validateWithTimeFormat("")
+}
+
+// validateWithTimeFormat is Validate that also accepts timestamps in layout.
+func (r *MemorizeRequest) validateWithTimeFormat(layout string) error {
 	if r.UserID == "" {
 		return fmt.Errorf("Memorize: UserID is required")
 	}
 	if r.AgentID == "" {
 		return fmt.Errorf("Memorize: AgentID is required")
 	}
+	if err := validateID("Memorize", "UserID", r.UserID); err != nil {
+		return err
+	}
+	if err := validateID("Memorize", "AgentID", r.AgentID); err != nil {
+		return err
+	}
 	if len(r.Conversation) == 0 && r.ConversationText == nil {
+		// A dated request without content is a common mistake; say so explicitly
+		if r.SessionDate != nil {
+			return fmt.Errorf("Memorize: SessionDate alone is not enough to memorize; provide Conversation (at least %d messages) or ConversationText with the content of the session", minConversationMessages)
+		}
 		return fmt.Errorf("Memorize: either Conversation or ConversationText must be provided")
 	}
-	if len(r.Conversation) > 0 && len(r.Conversation) < 3 {
-		return fmt.Errorf("Memorize: Conversation must contain at least 3 messages")
+	if len(r.Conversation) > 0 && len(r.Conversation) < minConversationMessages {
+		return errors.New(tooFewMessagesMessage)
+	}
+	for i, msg := range r.Conversation {
+		if err := msg.validateWithTimeFormat(layout); err != nil {
+			return fmt.Errorf("Memorize: Conversation[%d]: %w", i, err)
+		}
+	}
+	if r.Mode != "" && r.Mode != MemorizeModeAsync && r.Mode != MemorizeModeSync {
+		return fmt.Errorf("Memorize: Mode must be %q or %q", MemorizeModeAsync, MemorizeModeSync)
+	}
+	if r.Language != nil && !languageTagPattern.MatchString(*r.Language) {
+		return fmt.Errorf("Memorize: Language %q is not a valid BCP 47 language tag", *r.Language)
+	}
+	return nil
+}
+
+// validateNoConsecutiveDuplicates returns an error identifying the first message
+// in Conversation that repeats the role and content of the message before it.
+func (r *MemorizeRequest) validateNoConsecutiveDuplicates() error {
+	for i := 1; i < len(r.Conversation); i++ {
+		prev, curr := r.Conversation[i-1], r.Conversation[i]
+		if curr.Role == prev.Role && curr.Content == prev.Content {
+			return fmt.Errorf("Memorize: Conversation[%d] duplicates the previous message", i)
+		}
+	}
+	return nil
+}
+
+// validateMaxMessages checks that the conversation has at most max messages.
+func (r *MemorizeRequest) validateMaxMessages(max int) error {
+	if len(r.Conversation) > max {
+		return fmt.Errorf("Memorize: Conversation has %d messages, more than the maximum of %d; split it into smaller chunks", len(r.Conversation), max)
+	}
+	return nil
+}
+
+// validateChronological checks that the CreatedAt timestamps of the
+// conversation are non-decreasing. Messages without CreatedAt are skipped.
+// Timestamps are parsed with parseTimestamp and layout.
+func (r *MemorizeRequest) validateChronological(layout string) error {
+	var prev time.Time
+	hasPrev := false
+	for i, msg := range r.Conversation {
+		if msg.CreatedAt == nil {
+			continue
+		}
+		createdAt, ok := parseTimestamp(*msg.CreatedAt, layout)
+		if !ok {
+			return fmt.Errorf("Memorize: Conversation[%d]: CreatedAt %q is not a valid timestamp", i, *msg.CreatedAt)
+		}
+		if hasPrev && createdAt.Before(prev) {
+			return fmt.Errorf("Memorize: Conversation[%d] is out of order: CreatedAt %s is before the previous message", i, *msg.CreatedAt)
+		}
+		prev, hasPrev = createdAt, true
+	}
+	return nil
+}
+
+// isoDateLayouts lists the ISO 8601 layouts accepted for dates and timestamps.
+var isoDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// epochMillisThreshold separates Unix epoch seconds from milliseconds: epoch
+// seconds stay below it until the year 33658.
+const epochMillisThreshold = 1e12
+
+// parseTimestamp parses a date or timestamp. A non-empty layout, as set with
+// WithTimeFormat, is tried first, then the ISO 8601 layouts, then Unix epoch
+// seconds or milliseconds. Values without a time zone are read as UTC.
+func parseTimestamp(value, layout string) (time.Time, bool) {
+	if layout != "" {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+	for _, isoLayout := range isoDateLayouts {
+		if date, err := time.Parse(isoLayout, value); err == nil {
+			return date, true
+		}
+	}
+	return parseEpoch(value)
+}
+
+// parseEpoch parses Unix epoch seconds or milliseconds, telling them apart by
+// magnitude. Fractional seconds are supported.
+func parseEpoch(value string) (time.Time, bool) {
+	epoch, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(epoch) || math.IsInf(epoch, 0) || strings.ContainsAny(value, "eE") {
+		return time.Time{}, false
+	}
+	if math.Abs(epoch) >= epochMillisThreshold {
+		return time.UnixMilli(int64(epoch)).UTC(), true
+	}
+	seconds, fraction := math.Modf(epoch)
+	return time.Unix(int64(seconds), int64(math.Round(fraction*1e9))).UTC(), true
+}
+
+// validateSessionDateNotAfter returns an error if SessionDate is later than
+// latest or cannot be parsed with parseTimestamp and layout. Dates without a
+// time zone are read as UTC.
+func (r *MemorizeRequest) validateSessionDateNotAfter(latest time.Time, layout string) error {
+	if r.SessionDate == nil {
+		return nil
+	}
+	date, ok := parseTimestamp(*r.SessionDate, layout)
+	if !ok {
+		return fmt.Errorf("Memorize: SessionDate %q is not a valid date", *r.SessionDate)
+	}
+	if date.After(latest) {
+		return fmt.Errorf("Memorize: SessionDate %s is in the future", *r.SessionDate)
 	}
 	return nil
 }
 
+// PayloadSize returns the size in bytes of the JSON payload that Memorize
+// would send for this request with the default codec. Use Client.PayloadSize
+// for the size under a client's WithCodec and other options.
+func (r *MemorizeRequest) PayloadSize() (int, error) {
+	return encodedSize(defaultCodec, buildMemorizePayload(r))
+}
+
+// encodedSize returns the size in bytes of payload as encoded by codec.
+func encodedSize(codec Codec, payload interface{}) (int, error) {
+	jsonBytes, err := codec.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return len(jsonBytes), nil
+}
+
+// MessageCount returns the number of messages in Conversation.
+func (r *MemorizeRequest) MessageCount() int {
+	return len(r.Conversation)
+}
+
+// CollapseToText returns a copy of the request with Conversation rendered
+// into ConversationText and the array cleared. Each message is rendered by
+// format, or as "role: content\n" if format is nil. The receiver is not modified.
+func (r *MemorizeRequest) CollapseToText(format func(ConversationMessage) string) *MemorizeRequest {
+	if format == nil {
+		format = func(msg ConversationMessage) string {
+			return msg.Role + ": " + msg.Content + "\n"
+		}
+	}
+	var sb strings.Builder
+	for _, msg := range r.Conversation {
+		sb.WriteString(format(msg))
+	}
+	text := sb.String()
+
+	collapsed := *r
+	collapsed.Conversation = nil
+	collapsed.ConversationText = &text
+	return &collapsed
+}
+
 // Validate validates RetrieveRequest parameters.
 func (r *RetrieveRequest) Validate() error {
+	return r.validateWithTimeFormat("")
+}
+
+// validateWithTimeFormat is Validate that also accepts timestamps in layout.
+func (r *RetrieveRequest) validateWithTimeFormat(layout string) error {
 	if r.Query == nil {
 		return fmt.Errorf("Retrieve: Query is required")
 	}
@@ -182,6 +753,147 @@ func (r *RetrieveRequest) Validate() error {
 	if r.AgentID == "" {
 		return fmt.Errorf("Retrieve: AgentID is required")
 	}
+	if err := validateID("Retrieve", "UserID", r.UserID); err != nil {
+		return err
+	}
+	if err := validateID("Retrieve", "AgentID", r.AgentID); err != nil {
+		return err
+	}
+	if messages, ok := r.Query.([]ConversationMessage); ok {
+		if len(messages) == 0 {
+			return fmt.Errorf("Retrieve: Query must contain at least one message")
+		}
+		for i, msg := range messages {
+			if err := msg.validateWithTimeFormat(layout); err != nil {
+				return fmt.Errorf("Retrieve: Query[%d]: %w", i, err)
+			}
+		}
+	}
+	for memoryType, weight := range r.TypeWeights {
+		if weight < 0 {
+			return fmt.Errorf("Retrieve: TypeWeights[%q] must be non-negative", memoryType)
+		}
+	}
+	if r.Offset != nil && *r.Offset < 0 {
+		return fmt.Errorf("Retrieve: Offset must be non-negative")
+	}
+	if r.Limit != nil && *r.Limit < 0 {
+		return fmt.Errorf("Retrieve: Limit must be non-negative")
+	}
+	if r.RecencyBoost != nil && (*r.RecencyBoost < 0 || *r.RecencyBoost > 1) {
+		return fmt.Errorf("Retrieve: RecencyBoost must be between 0 and 1")
+	}
+	for key, value := range r.ExtraParams {
+		if key == "" {
+			return fmt.Errorf("Retrieve: ExtraParams keys must be non-empty")
+		}
+		if value == "" {
+			return fmt.Errorf("Retrieve: ExtraParams[%q] must be non-empty", key)
+		}
+	}
+	return nil
+}
+
+// Validate validates ReprocessRequest parameters.
+func (r *ReprocessRequest) Validate() error {
+	if r.UserID == "" {
+		return fmt.Errorf("ReprocessResource: UserID is required")
+	}
+	if r.AgentID == "" {
+		return fmt.Errorf("ReprocessResource: AgentID is required")
+	}
+	if err := validateID("ReprocessResource", "UserID", r.UserID); err != nil {
+		return err
+	}
+	if err := validateID("ReprocessResource", "AgentID", r.AgentID); err != nil {
+		return err
+	}
+	if r.ResourceID == "" && r.ResourceURL == "" {
+		return fmt.Errorf("ReprocessResource: either ResourceID or ResourceURL must be provided")
+	}
+	return nil
+}
+
+// Validate validates GetMemoryItemRequest parameters.
+func (r *GetMemoryItemRequest) Validate() error {
+	if r.UserID == "" {
+		return fmt.Errorf("GetMemoryItem: UserID is required")
+	}
+	if r.ID == "" {
+		return fmt.Errorf("GetMemoryItem: ID is required")
+	}
+	if err := validateID("GetMemoryItem", "UserID", r.UserID); err != nil {
+		return err
+	}
+	if err := validateID("GetMemoryItem", "ID", r.ID); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Validate validates RecentMemoriesRequest parameters.
+func (r *RecentMemoriesRequest) Validate() error {
+	if r.UserID == "" {
+		return fmt.Errorf("RecentMemories: UserID is required")
+	}
+	if r.AgentID == "" {
+		return fmt.Errorf("RecentMemories: AgentID is required")
+	}
+	if err := validateID("RecentMemories", "UserID", r.UserID); err != nil {
+		return err
+	}
+	if err := validateID("RecentMemories", "AgentID", r.AgentID); err != nil {
+		return err
+	}
+	if r.Limit < 0 {
+		return fmt.Errorf("RecentMemories: Limit must be non-negative")
+	}
+	return nil
+}
+
+// Validate validates RetrieveSimilarRequest parameters.
+func (r *RetrieveSimilarRequest) Validate() error {
+	if r.UserID == "" {
+		return fmt.Errorf("RetrieveSimilar: UserID is required")
+	}
+	if r.AgentID == "" {
+		return fmt.Errorf("RetrieveSimilar: AgentID is required")
+	}
+	if err := validateID("RetrieveSimilar", "UserID", r.UserID); err != nil {
+		return err
+	}
+	if err := validateID("RetrieveSimilar", "AgentID", r.AgentID); err != nil {
+		return err
+	}
+	if r.ItemID == "" && strings.TrimSpace(r.Content) == "" {
+		return fmt.Errorf("RetrieveSimilar: either ItemID or Content must be provided")
+	}
+	return nil
+}
+
+// Validate validates ReassignRequest parameters.
+func (r *ReassignRequest) Validate() error {
+	if r.UserID == "" {
+		return fmt.Errorf("ReassignMemories: UserID is required")
+	}
+	if r.FromAgentID == "" {
+		return fmt.Errorf("ReassignMemories: FromAgentID is required")
+	}
+	if r.ToAgentID == "" {
+		return fmt.Errorf("ReassignMemories: ToAgentID is required")
+	}
+	if err := validateID("ReassignMemories", "UserID", r.UserID); err != nil {
+		return err
+	}
+	if err := validateID("ReassignMemories", "FromAgentID", r.FromAgentID); err != nil {
+		return err
+	}
+	if err := validateID("ReassignMemories", "ToAgentID", r.ToAgentID); err != nil {
+		return err
+	}
+	if r.FromAgentID == r.ToAgentID {
+		return fmt.Errorf("ReassignMemories: FromAgentID and ToAgentID must differ")
+	}
 	return nil
 }
 
@@ -190,5 +902,25 @@ func (r *ListCategoriesRequest) Validate() error {
 	if r.UserID == "" {
 		return fmt.Errorf("ListCategories: UserID is required")
 	}
+	if err := validateID("ListCategories", "UserID", r.UserID); err != nil {
+		return err
+	}
+	if r.AgentID != nil {
+		if err := validateID("ListCategories", "AgentID", *r.AgentID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateID checks that an identifier contains no whitespace or control
+// characters, which typically come from copy-paste mistakes and cause subtle
+// scoping bugs or malformed URLs.
+func validateID(operation, field, value string) error {
+	for _, r := range value {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return invalidRequest(fmt.Errorf("%s: %s must not contain whitespace or control characters", operation, field))
+		}
+	}
 	return nil
 }
@@ -3,8 +3,10 @@
 package memu
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 // Helper function for creating string pointers.
@@ -41,6 +43,73 @@ func TestMemoryItem_NilFields(t *testing.T) {
 	}
 }
 
+func TestMemoryItem_RawExtra(t *testing.T) {
+	var item MemoryItem
+	data := `{"id":"item_1","content":"Likes tea","memory_type":"preference","source":"chat","score":0.9}`
+	if err := json.Unmarshal([]byte(data), &item); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if item.Content == nil || *item.Content != "Likes tea" {
+		t.Errorf("expected Content 'Likes tea', got %v", item.Content)
+	}
+	if item.MemoryType == nil || *item.MemoryType != "preference" {
+		t.Errorf("expected MemoryType 'preference', got %v", item.MemoryType)
+	}
+	if len(item.RawExtra) != 2 {
+		t.Fatalf("expected 2 extra fields, got %v", item.RawExtra)
+	}
+	if item.ID == nil || *item.ID != "item_1" {
+		t.Errorf("expected ID 'item_1', got %v", item.ID)
+	}
+	if item.RawExtra["source"] != "chat" {
+		t.Errorf("expected RawExtra['source'] 'chat', got %v", item.RawExtra["source"])
+	}
+	if item.RawExtra["score"] != 0.9 {
+		t.Errorf("expected RawExtra['score'] 0.9, got %v", item.RawExtra["score"])
+	}
+}
+
+func TestMemoryItem_RawExtra_NoExtras(t *testing.T) {
+	var item MemoryItem
+	if err := json.Unmarshal([]byte(`{"content":"Likes tea"}`), &item); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if item.RawExtra != nil {
+		t.Errorf("expected RawExtra to be nil, got %v", item.RawExtra)
+	}
+}
+
+func TestMemoryItem_Preview(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  *string
+		n        int
+		expected string
+	}{
+		{"short", strPtr("Likes tea"), 20, "Likes tea"},
+		{"exact", strPtr("Likes tea"), 9, "Likes tea"},
+		{"truncated", strPtr("Likes green tea"), 5, "Likes..."},
+		{"multibyte", strPtr("喜欢喝绿茶和红茶"), 4, "喜欢喝绿..."},
+		{"emoji", strPtr("🍵🍰☕️"), 2, "🍵🍰..."},
+		{"zero", strPtr("Likes tea"), 0, "..."},
+		{"nil", nil, 10, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &MemoryItem{Content: tt.content}
+			got := item.Preview(tt.n)
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("expected valid UTF-8, got %q", got)
+			}
+		})
+	}
+}
+
 // TestMemoryCategory tests MemoryCategory model.
 func TestMemoryCategory(t *testing.T) {
 	name := "preferences"
@@ -64,6 +133,37 @@ func TestMemoryCategory(t *testing.T) {
 	}
 }
 
+func TestMemoryCategory_SummaryPreview(t *testing.T) {
+	category := &MemoryCategory{Summary: strPtr("Prefers Japanese cuisine — sushi, ramen")}
+	if got := category.SummaryPreview(26); got != "Prefers Japanese cuisine —..." {
+		t.Errorf("expected rune-safe truncation, got %q", got)
+	}
+	if got := category.SummaryPreview(100); got != *category.Summary {
+		t.Errorf("expected full summary, got %q", got)
+	}
+	if got := (&MemoryCategory{}).SummaryPreview(10); got != "" {
+		t.Errorf("expected empty preview for nil summary, got %q", got)
+	}
+}
+
+func TestMemoryCategory_RawExtra(t *testing.T) {
+	var category MemoryCategory
+	data := `{"name":"preferences","user_id":"u1","item_count":3}`
+	if err := json.Unmarshal([]byte(data), &category); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if category.Name == nil || *category.Name != "preferences" {
+		t.Errorf("expected Name 'preferences', got %v", category.Name)
+	}
+	if category.UserID == nil || *category.UserID != "u1" {
+		t.Errorf("expected UserID 'u1', got %v", category.UserID)
+	}
+	if len(category.RawExtra) != 1 || category.RawExtra["item_count"] != float64(3) {
+		t.Errorf("expected RawExtra with item_count 3, got %v", category.RawExtra)
+	}
+}
+
 // TestMemoryResource tests MemoryResource model.
 func TestMemoryResource(t *testing.T) {
 	url := "https://example.com/chat.json"
@@ -116,6 +216,24 @@ func TestTaskStatus(t *testing.T) {
 	}
 }
 
+func TestTaskStatus_RawExtra(t *testing.T) {
+	var status TaskStatus
+	data := `{"task_id":"t1","status":"SUCCESS","progress":100}`
+	if err := json.Unmarshal([]byte(data), &status); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if status.TaskID != "t1" {
+		t.Errorf("expected TaskID 't1', got '%s'", status.TaskID)
+	}
+	if status.Status != TaskStatusSuccess {
+		t.Errorf("expected Status SUCCESS, got '%s'", status.Status)
+	}
+	if len(status.RawExtra) != 1 || status.RawExtra["progress"] != float64(100) {
+		t.Errorf("expected RawExtra with progress 100, got %v", status.RawExtra)
+	}
+}
+
 func TestTaskStatusEnum_Values(t *testing.T) {
 	tests := []struct {
 		status TaskStatusEnum
@@ -197,6 +315,59 @@ func TestRetrieveResult_WithData(t *testing.T) {
 	}
 }
 
+func TestRetrieveResult_WasRewritten(t *testing.T) {
+	tests := []struct {
+		name      string
+		result    *RetrieveResult
+		rewritten string
+		expected  bool
+	}{
+		{"nil result", nil, "", false},
+		{"nil rewritten query", &RetrieveResult{}, "", false},
+		{"identical", &RetrieveResult{RewrittenQuery: strPtr("food")}, "food", false},
+		{"differing", &RetrieveResult{RewrittenQuery: strPtr("food preferences")}, "food preferences", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.WasRewritten("food"); got != tt.expected {
+				t.Errorf("expected WasRewritten %v, got %v", tt.expected, got)
+			}
+			if got := tt.result.GetRewrittenQuery(); got != tt.rewritten {
+				t.Errorf("expected GetRewrittenQuery '%s', got '%s'", tt.rewritten, got)
+			}
+		})
+	}
+}
+
+func TestRetrieveResult_Normalize(t *testing.T) {
+	marshal := func(r *RetrieveResult) string {
+		data, err := json.Marshal(r)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return string(data)
+	}
+
+	result := &RetrieveResult{Items: []*MemoryItem{}}
+	if got := marshal(result); got != `{"categories":null,"items":[],"resources":null}` {
+		t.Errorf("unexpected JSON before normalization: %s", got)
+	}
+
+	if got := marshal(result.Normalize(false)); got != `{"categories":[],"items":[],"resources":[]}` {
+		t.Errorf("expected empty arrays after normalization, got %s", got)
+	}
+
+	if got := marshal(result.Normalize(true)); got != `{"categories":null,"items":null,"resources":null}` {
+		t.Errorf("expected nulls after nil normalization, got %s", got)
+	}
+
+	var nilResult *RetrieveResult
+	if nilResult.Normalize(false) != nil {
+		t.Error("expected nil result to stay nil")
+	}
+}
+
 // TestConversationMessage tests ConversationMessage model.
 func TestConversationMessage(t *testing.T) {
 	name := "John"
@@ -220,6 +391,118 @@ func TestConversationMessage(t *testing.T) {
 	}
 }
 
+// TestConversationMessage_ToolRoundTrip tests serializing a tool message.
+func TestConversationMessage_ToolRoundTrip(t *testing.T) {
+	msg := ConversationMessage{
+		Role:       RoleTool,
+		Content:    `{"temperature": 21}`,
+		ToolCallID: strPtr("call_123"),
+		ToolName:   strPtr("get_weather"),
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if raw["tool_call_id"] != "call_123" {
+		t.Errorf("expected tool_call_id 'call_123', got %v", raw["tool_call_id"])
+	}
+	if raw["tool_name"] != "get_weather" {
+		t.Errorf("expected tool_name 'get_weather', got %v", raw["tool_name"])
+	}
+
+	var decoded ConversationMessage
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.ToolCallID == nil || *decoded.ToolCallID != "call_123" {
+		t.Errorf("expected ToolCallID 'call_123', got %v", decoded.ToolCallID)
+	}
+	if decoded.ToolName == nil || *decoded.ToolName != "get_weather" {
+		t.Errorf("expected ToolName 'get_weather', got %v", decoded.ToolName)
+	}
+}
+
+// TestConversationMessage_OmitsToolFields tests that tool fields are omitted when unset.
+func TestConversationMessage_OmitsToolFields(t *testing.T) {
+	data, err := json.Marshal(ConversationMessage{Role: RoleUser, Content: "Hi"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "tool_call_id") || strings.Contains(string(data), "tool_name") {
+		t.Errorf("expected tool fields to be omitted, got %s", data)
+	}
+}
+
+// TestIsValidRole tests the allowed conversation role set.
+func TestIsValidRole(t *testing.T) {
+	for _, role := range []string{"user", "assistant", "system", "tool", "function"} {
+		if !IsValidRole(role) {
+			t.Errorf("expected role %q to be valid", role)
+		}
+	}
+	for _, role := range []string{"", "User", "bot", "developer"} {
+		if IsValidRole(role) {
+			t.Errorf("expected role %q to be invalid", role)
+		}
+	}
+}
+
+// TestConversationMessage_Validate tests per-message validation.
+func TestConversationMessage_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     ConversationMessage
+		wantErr string
+	}{
+		{"valid", ConversationMessage{Role: RoleUser, Content: "Hello"}, ""},
+		{"valid tool", ConversationMessage{Role: RoleTool, Content: "{}", ToolCallID: strPtr("call_1")}, ""},
+		{"valid timestamp", ConversationMessage{Role: RoleAssistant, Content: "Hi", CreatedAt: strPtr("2024-01-15T10:30:00Z")}, ""},
+		{"empty role", ConversationMessage{Content: "Hello"}, "Role"},
+		{"unknown role", ConversationMessage{Role: "bot", Content: "Hello"}, "Role"},
+		{"empty content", ConversationMessage{Role: RoleUser}, "Content"},
+		{"blank content", ConversationMessage{Role: RoleUser, Content: "  \n"}, "Content"},
+		{"bad timestamp", ConversationMessage{Role: RoleUser, Content: "Hello", CreatedAt: strPtr("15/01/2024")}, "CreatedAt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.msg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error mentioning %s, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestMemorizeRequest_Validate_InvalidMessage tests that message errors are reported with their index.
+func TestMemorizeRequest_Validate_InvalidMessage(t *testing.T) {
+	req := &MemorizeRequest{
+		UserID:  "user_123",
+		AgentID: "agent_456",
+		Conversation: []ConversationMessage{
+			{Role: "user", Content: "Hello"},
+			{Role: "assistant", Content: ""},
+			{Role: "user", Content: "Bye"},
+		},
+	}
+
+	err := req.Validate()
+	if err == nil || !strings.Contains(err.Error(), "Conversation[1]") {
+		t.Errorf("expected error for Conversation[1], got %v", err)
+	}
+}
+
 // TestMemorizeRequest_Validate tests MemorizeRequest validation.
 func TestMemorizeRequest_Validate_Valid(t *testing.T) {
 	req := &MemorizeRequest{
@@ -325,6 +608,272 @@ func TestMemorizeRequest_Validate_WithConversationText(t *testing.T) {
 }
 
 // TestRetrieveRequest_Validate tests RetrieveRequest validation.
+func TestMemorizeRequest_Validate_InvalidMode(t *testing.T) {
+	req := &MemorizeRequest{
+		UserID:           "user_123",
+		AgentID:          "agent_456",
+		ConversationText: strPtr("user: hi"),
+		Mode:             "batch",
+	}
+
+	err := req.Validate()
+	if err == nil {
+		t.Fatal("expected error for invalid Mode")
+	}
+	if !strings.Contains(err.Error(), "Mode") {
+		t.Errorf("expected error message to contain 'Mode', got: %v", err)
+	}
+}
+
+func TestMemorizeRequest_ConsecutiveDuplicates(t *testing.T) {
+	req := &MemorizeRequest{
+		UserID:  "user_123",
+		AgentID: "agent_456",
+		Conversation: []ConversationMessage{
+			{Role: "user", Content: "Hello"},
+			{Role: "assistant", Content: "Hi there"},
+			{Role: "assistant", Content: "Hi there"},
+			{Role: "user", Content: "How are you?"},
+		},
+	}
+
+	err := req.validateNoConsecutiveDuplicates()
+	if err == nil {
+		t.Fatal("expected error for consecutive duplicate messages")
+	}
+	if !strings.Contains(err.Error(), "Conversation[2]") {
+		t.Errorf("expected error message to contain 'Conversation[2]', got: %v", err)
+	}
+}
+
+func TestMemorizeRequest_NoConsecutiveDuplicates(t *testing.T) {
+	req := &MemorizeRequest{
+		UserID:  "user_123",
+		AgentID: "agent_456",
+		Conversation: []ConversationMessage{
+			{Role: "user", Content: "Hello"},
+			{Role: "assistant", Content: "Hello"},
+			{Role: "user", Content: "Hello"},
+		},
+	}
+
+	if err := req.validateNoConsecutiveDuplicates(); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestMemorizeRequest_PayloadSize(t *testing.T) {
+	req := &MemorizeRequest{
+		UserID:  "user_123",
+		AgentID: "agent_456",
+		Conversation: []ConversationMessage{
+			{Role: "user", Content: "Hello"},
+			{Role: "assistant", Content: "Hi there"},
+			{Role: "user", Content: "How are you?"},
+		},
+	}
+
+	size, err := req.PayloadSize()
+	if err != nil {
+		t.Fatalf("PayloadSize failed: %v", err)
+	}
+	jsonBytes, err := json.Marshal(buildMemorizePayload(req))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if size != len(jsonBytes) {
+		t.Errorf("expected size %d, got %d", len(jsonBytes), size)
+	}
+}
+
+func TestMemorizeRequest_MessageCount(t *testing.T) {
+	req := &MemorizeRequest{
+		Conversation: []ConversationMessage{
+			{Role: "user", Content: "Hello"},
+			{Role: "assistant", Content: "Hi there"},
+		},
+	}
+	if req.MessageCount() != 2 {
+		t.Errorf("expected MessageCount 2, got %d", req.MessageCount())
+	}
+
+	textReq := &MemorizeRequest{ConversationText: strPtr("user: Hello")}
+	if textReq.MessageCount() != 0 {
+		t.Errorf("expected MessageCount 0, got %d", textReq.MessageCount())
+	}
+}
+
+func TestMemorizeRequest_Language(t *testing.T) {
+	valid := []string{"en", "EN", "zh-Hans", "pt-BR", "sr-Latn-RS", "yue"}
+	for _, tag := range valid {
+		req := &MemorizeRequest{
+			UserID:           "user_123",
+			AgentID:          "agent_456",
+			ConversationText: strPtr("user: hi"),
+			Language:         strPtr(tag),
+		}
+		if err := req.Validate(); err != nil {
+			t.Errorf("expected %q to be valid, got: %v", tag, err)
+		}
+		if payload := buildMemorizePayload(req); payload["language"] != tag {
+			t.Errorf("expected payload language %q, got %v", tag, payload["language"])
+		}
+	}
+
+	invalid := []string{"", "e", "english language", "en_US", "en-", "-en", "en--US", "zh-Hans-toolongsubtag"}
+	for _, tag := range invalid {
+		req := &MemorizeRequest{
+			UserID:           "user_123",
+			AgentID:          "agent_456",
+			ConversationText: strPtr("user: hi"),
+			Language:         strPtr(tag),
+		}
+		err := req.Validate()
+		if err == nil || !strings.Contains(err.Error(), "Language") {
+			t.Errorf("expected Language error for %q, got %v", tag, err)
+		}
+	}
+
+	noLanguage := &MemorizeRequest{UserID: "user_123", AgentID: "agent_456", ConversationText: strPtr("user: hi")}
+	if _, ok := buildMemorizePayload(noLanguage)["language"]; ok {
+		t.Error("expected language to be omitted when unset")
+	}
+}
+
+func TestMemorizeRequest_CollapseToText(t *testing.T) {
+	req := &MemorizeRequest{
+		UserID:  "user_123",
+		AgentID: "agent_456",
+		Conversation: []ConversationMessage{
+			{Role: "user", Content: "I like tea"},
+			{Role: "assistant", Content: "Noted"},
+			{Role: "user", Content: "And biscuits"},
+		},
+	}
+
+	collapsed := req.CollapseToText(nil)
+	expected := "user: I like tea\nassistant: Noted\nuser: And biscuits\n"
+	if collapsed.ConversationText == nil || *collapsed.ConversationText != expected {
+		t.Fatalf("expected ConversationText %q, got %v", expected, collapsed.ConversationText)
+	}
+	if collapsed.Conversation != nil {
+		t.Errorf("expected Conversation to be cleared, got %v", collapsed.Conversation)
+	}
+	if collapsed.UserID != "user_123" || collapsed.AgentID != "agent_456" {
+		t.Errorf("expected IDs to be preserved, got %q %q", collapsed.UserID, collapsed.AgentID)
+	}
+	if err := collapsed.Validate(); err != nil {
+		t.Errorf("expected collapsed request to validate, got: %v", err)
+	}
+	if req.MessageCount() != 3 || req.ConversationText != nil {
+		t.Error("expected original request to be unchanged")
+	}
+
+	custom := req.CollapseToText(func(msg ConversationMessage) string {
+		return "[" + msg.Role + "] " + msg.Content + "\n"
+	})
+	if *custom.ConversationText != "[user] I like tea\n[assistant] Noted\n[user] And biscuits\n" {
+		t.Errorf("unexpected custom rendering %q", *custom.ConversationText)
+	}
+}
+
+func TestReassignRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      ReassignRequest
+		contains string
+	}{
+		{"valid", ReassignRequest{UserID: "u1", FromAgentID: "a1", ToAgentID: "a2"}, ""},
+		{"missing user", ReassignRequest{FromAgentID: "a1", ToAgentID: "a2"}, "UserID"},
+		{"missing from", ReassignRequest{UserID: "u1", ToAgentID: "a2"}, "FromAgentID"},
+		{"missing to", ReassignRequest{UserID: "u1", FromAgentID: "a1"}, "ToAgentID"},
+		{"same agent", ReassignRequest{UserID: "u1", FromAgentID: "a1", ToAgentID: "a1"}, "must differ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.contains == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("expected error containing %q, got %v", tt.contains, err)
+			}
+		})
+	}
+}
+
+func TestMemorizeRequest_Validate_OnlySessionDate(t *testing.T) {
+	req := &MemorizeRequest{
+		UserID:      "user_123",
+		AgentID:     "agent_456",
+		SessionDate: strPtr("2024-01-15"),
+	}
+	err := req.Validate()
+	if err == nil {
+		t.Fatal("expected error for a request with only SessionDate, got nil")
+	}
+	for _, want := range []string{"SessionDate alone", "Conversation (at least 3 messages)", "ConversationText"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %q, got %q", want, err.Error())
+		}
+	}
+
+	// Without SessionDate the generic message is kept
+	req.SessionDate = nil
+	if err := req.Validate(); err == nil || strings.Contains(err.Error(), "SessionDate") {
+		t.Errorf("expected the generic message, got %v", err)
+	}
+}
+
+func TestMemorizeResult_IsEmpty(t *testing.T) {
+	if !(&MemorizeResult{}).IsEmpty() {
+		t.Error("expected zero result to be empty")
+	}
+	for _, result := range []*MemorizeResult{
+		{TaskID: strPtr("t1")},
+		{Status: strPtr("PENDING")},
+		{Items: []*MemoryItem{{Content: strPtr("Likes tea")}}},
+	} {
+		if result.IsEmpty() {
+			t.Errorf("expected %+v not to be empty", result)
+		}
+	}
+}
+
+func TestRetrieveSimilarRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      RetrieveSimilarRequest
+		contains string
+	}{
+		{"item seed", RetrieveSimilarRequest{UserID: "u1", AgentID: "a1", ItemID: "item_1"}, ""},
+		{"content seed", RetrieveSimilarRequest{UserID: "u1", AgentID: "a1", Content: "Likes tea"}, ""},
+		{"missing user", RetrieveSimilarRequest{AgentID: "a1", ItemID: "item_1"}, "UserID"},
+		{"missing agent", RetrieveSimilarRequest{UserID: "u1", ItemID: "item_1"}, "AgentID"},
+		{"missing seed", RetrieveSimilarRequest{UserID: "u1", AgentID: "a1"}, "ItemID or Content"},
+		{"blank content", RetrieveSimilarRequest{UserID: "u1", AgentID: "a1", Content: "   "}, "ItemID or Content"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.contains == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("expected error containing %q, got %v", tt.contains, err)
+			}
+		})
+	}
+}
+
 func TestRetrieveRequest_Validate_Valid(t *testing.T) {
 	req := &RetrieveRequest{
 		Query:   "What are the user's hobbies?",
@@ -392,7 +941,112 @@ func TestRetrieveRequest_Validate_ConversationQuery(t *testing.T) {
 	}
 }
 
+func TestRetrieveRequest_Validate_InvalidConversationQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    []ConversationMessage
+		contains string
+	}{
+		{"empty", []ConversationMessage{}, "at least one message"},
+		{"nil slice", nil, "at least one message"},
+		{"bad role", []ConversationMessage{{Role: "user", Content: "Hi"}, {Role: "robot", Content: "Beep"}}, "Query[1]"},
+		{"blank content", []ConversationMessage{{Role: "user", Content: "  "}}, "Content is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := NewConversationRetrieve("user_123", "agent_456", tt.query)
+			err := req.Validate()
+			if err == nil {
+				t.Fatal("expected error for invalid conversation query")
+			}
+			if !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("expected error to contain %q, got: %v", tt.contains, err)
+			}
+		})
+	}
+}
+
 // TestListCategoriesRequest_Validate tests ListCategoriesRequest validation.
+func TestNewStringRetrieve(t *testing.T) {
+	req := NewStringRetrieve("user_123", "agent_456", "What does the user like?")
+
+	if err := req.Validate(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if req.UserID != "user_123" || req.AgentID != "agent_456" {
+		t.Errorf("expected user_123/agent_456, got %s/%s", req.UserID, req.AgentID)
+	}
+	if query, ok := req.Query.(string); !ok || query != "What does the user like?" {
+		t.Errorf("expected string query, got %v", req.Query)
+	}
+}
+
+func TestNewConversationRetrieve(t *testing.T) {
+	msgs := []ConversationMessage{
+		{Role: "user", Content: "What do they like?"},
+		{Role: "assistant", Content: "Several things."},
+	}
+	req := NewConversationRetrieve("user_123", "agent_456", msgs)
+
+	if err := req.Validate(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if query, ok := req.Query.([]ConversationMessage); !ok || len(query) != 2 {
+		t.Errorf("expected conversation query, got %v", req.Query)
+	}
+}
+
+func TestRetrieveRequest_Validate_NegativeTypeWeight(t *testing.T) {
+	req := &RetrieveRequest{
+		Query:       "What are the user's hobbies?",
+		UserID:      "user_123",
+		AgentID:     "agent_456",
+		TypeWeights: map[string]float64{"preference": 2.0, "skill": -1.0},
+	}
+
+	err := req.Validate()
+	if err == nil {
+		t.Fatal("expected error for negative type weight")
+	}
+	if !strings.Contains(err.Error(), "skill") {
+		t.Errorf("expected error message to contain 'skill', got: %v", err)
+	}
+}
+
+func TestReprocessRequest_Validate(t *testing.T) {
+	valid := []*ReprocessRequest{
+		{UserID: "user_123", AgentID: "agent_456", ResourceID: "res_1"},
+		{UserID: "user_123", AgentID: "agent_456", ResourceURL: "https://example.com/chat.json"},
+	}
+	for _, req := range valid {
+		if err := req.Validate(); err != nil {
+			t.Errorf("expected no error, got: %v", err)
+		}
+	}
+
+	err := (&ReprocessRequest{UserID: "user_123", AgentID: "agent_456"}).Validate()
+	if err == nil || !strings.Contains(err.Error(), "ResourceID or ResourceURL") {
+		t.Errorf("expected missing reference error, got: %v", err)
+	}
+}
+
+func TestGetMemoryItemRequest_Validate(t *testing.T) {
+	if err := (&GetMemoryItemRequest{UserID: "user_123", ID: "item_1"}).Validate(); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+
+	err := (&GetMemoryItemRequest{ID: "item_1"}).Validate()
+	if err == nil || !strings.Contains(err.Error(), "UserID") {
+		t.Errorf("expected UserID error, got: %v", err)
+	}
+
+	err = (&GetMemoryItemRequest{UserID: "user_123"}).Validate()
+	if err == nil || !strings.Contains(err.Error(), "ID") {
+		t.Errorf("expected ID error, got: %v", err)
+	}
+}
+
 func TestListCategoriesRequest_Validate_Valid(t *testing.T) {
 	req := &ListCategoriesRequest{
 		UserID: "user_123",
@@ -428,3 +1082,32 @@ func TestListCategoriesRequest_Validate_MissingUserID(t *testing.T) {
 		t.Errorf("expected error message to contain 'UserID', got: %v", err)
 	}
 }
+
+// TestValidateID tests identifier character validation.
+func TestValidateID(t *testing.T) {
+	invalid := []string{"user 123", "user_123\n", "\tuser", "user\x00id", "user\u00a0id"}
+	for _, id := range invalid {
+		req := &RetrieveRequest{Query: "q", UserID: id, AgentID: "agent_456"}
+		err := req.Validate()
+		if err == nil {
+			t.Errorf("expected error for UserID %q", id)
+			continue
+		}
+		if !strings.Contains(err.Error(), "UserID") {
+			t.Errorf("expected error message to contain 'UserID', got: %v", err)
+		}
+	}
+
+	req := &MemorizeRequest{
+		UserID:           "user_123",
+		AgentID:          "agent 456",
+		ConversationText: strPtr("user: hi"),
+	}
+	if err := req.Validate(); err == nil || !strings.Contains(err.Error(), "AgentID") {
+		t.Errorf("expected AgentID error, got: %v", err)
+	}
+
+	if err := validateID("Test", "ID", "user-123_abc.def"); err != nil {
+		t.Errorf("expected no error for valid ID, got: %v", err)
+	}
+}
@@ -1,7 +1,10 @@
 package memu
 
 import (
+	"context"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -12,6 +15,26 @@ type Option func(*Client)
 func WithBaseURL(url string) Option {
 	return func(c *Client) {
 		c.baseURL = url
+		c.baseURLSet = true
+	}
+}
+
+// WithAPIVersion sets the API version used in request paths, e.g. "v4" for
+// paths under /api/v4/. The default is DefaultAPIVersion. Surrounding
+// slashes are ignored, so "/v4/" is the same as "v4"; NewClient returns an
+// error for an empty version or one containing a slash.
+func WithAPIVersion(version string) Option {
+	return func(c *Client) {
+		c.apiVersion = strings.Trim(strings.TrimSpace(version), "/")
+	}
+}
+
+// WithRegion selects the API host for a region (e.g., "us" or "eu").
+// NewClient returns an error for unknown regions. WithBaseURL takes
+// precedence regardless of option order.
+func WithRegion(region string) Option {
+	return func(c *Client) {
+		c.region = region
 	}
 }
 
@@ -23,12 +46,48 @@ func WithTimeout(timeout time.Duration) Option {
 }
 
 // WithMaxRetries sets the maximum number of retry attempts for failed requests.
+// When the default retry policy is active, its MaxRetries is updated as well.
 func WithMaxRetries(retries int) Option {
 	return func(c *Client) {
 		c.maxRetries = retries
+		updateDefaultRetryConfig(c, func(config *RetryConfig) {
+			config.MaxRetries = retries
+		})
+	}
+}
+
+// WithBaseDelay sets the base delay for exponential backoff of the active
+// default retry policy. It has no effect on custom retry policies.
+func WithBaseDelay(delay time.Duration) Option {
+	return func(c *Client) {
+		updateDefaultRetryConfig(c, func(config *RetryConfig) {
+			config.BaseDelay = delay
+		})
 	}
 }
 
+// WithBackoffCap sets the maximum delay between retries of the active default
+// retry policy. It has no effect on custom retry policies.
+func WithBackoffCap(maxDelay time.Duration) Option {
+	return func(c *Client) {
+		updateDefaultRetryConfig(c, func(config *RetryConfig) {
+			config.MaxDelay = maxDelay
+		})
+	}
+}
+
+// updateDefaultRetryConfig applies update to a copy of the active default
+// retry policy's configuration, leaving any caller-owned RetryConfig untouched.
+func updateDefaultRetryConfig(c *Client, update func(config *RetryConfig)) {
+	policy, ok := c.retryPolicy.(*defaultRetryPolicy)
+	if !ok {
+		return
+	}
+	config := *policy.config
+	update(&config)
+	c.retryPolicy = &defaultRetryPolicy{config: &config}
+}
+
 // WithHTTPClient sets a custom HTTP client.
 func WithHTTPClient(client *http.Client) Option {
 	return func(c *Client) {
@@ -42,3 +101,415 @@ func WithRetryPolicy(policy RetryPolicy) Option {
 		c.retryPolicy = policy
 	}
 }
+
+// WithRequestIDExtractor sets a function that extracts a request ID from the
+// request context. When it returns a non-empty value, the value is sent as the
+// X-Correlation-Id header.
+func WithRequestIDExtractor(extractor func(ctx context.Context) string) Option {
+	return func(c *Client) {
+		c.requestIDExtractor = extractor
+	}
+}
+
+// WithPayloadSchemaValidation enables validation of built Memorize and Retrieve
+// payloads against an embedded schema before sending. Violations are returned
+// as a SchemaValidationError. Disabled by default.
+func WithPayloadSchemaValidation() Option {
+	return func(c *Client) {
+		c.validatePayloadSchema = true
+	}
+}
+
+// WithMaxRequestBytes sets the maximum request body size in bytes. Requests
+// whose marshaled body exceeds the limit fail with a RequestTooLargeError
+// without being sent. A value of 0 disables the check.
+func WithMaxRequestBytes(maxBytes int64) Option {
+	return func(c *Client) {
+		c.maxRequestBytes = maxBytes
+	}
+}
+
+// WithMaxResponseBytes sets the maximum response body size in bytes, counted
+// after gzip decoding. Larger responses fail with a ResponseTooLargeError and
+// are not retried. A value of 0 disables the check. Default:
+// DefaultMaxResponseBytes (32 MiB).
+func WithMaxResponseBytes(maxBytes int64) Option {
+	return func(c *Client) {
+		c.maxResponseBytes = maxBytes
+	}
+}
+
+// WithRetryNonIdempotent controls whether Memorize is retried after network
+// errors that may have reached the server when no IdempotencyKey is set, which
+// can create duplicate tasks. Failures to connect are always retried, and
+// read-only calls are unaffected. Default: false.
+func WithRetryNonIdempotent(retry bool) Option {
+	return func(c *Client) {
+		c.retryNonIdempotent = retry
+	}
+}
+
+// WithRetryOnTimeout controls whether transport-level timeouts (such as the
+// HTTP client timeout) are retried. Cancellation or expiry of the caller's
+// context is never retried. Default: true.
+func WithRetryOnTimeout(retry bool) Option {
+	return func(c *Client) {
+		c.retryOnTimeout = retry
+	}
+}
+
+// WithRejectDuplicateMessages makes Memorize reject conversations containing
+// consecutive messages with identical role and content. Disabled by default.
+func WithRejectDuplicateMessages() Option {
+	return func(c *Client) {
+		c.rejectDuplicateMessages = true
+	}
+}
+
+// WithRequireChronologicalMessages makes Memorize reject conversations whose
+// message CreatedAt timestamps are not in non-decreasing order. Messages
+// without CreatedAt are skipped. Disabled by default.
+func WithRequireChronologicalMessages() Option {
+	return func(c *Client) {
+		c.requireChronologicalMessages = true
+	}
+}
+
+// WithMaxConversationMessages makes Memorize reject conversations with more
+// than max messages, matching the backend's cap. Values <= 0 disable the check,
+// which is the default. The minimum of 3 messages still applies.
+func WithMaxConversationMessages(max int) Option {
+	return func(c *Client) {
+		c.maxConversationMessages = max
+	}
+}
+
+// WithRejectFutureSessionDate makes Memorize reject a SessionDate more than
+// tolerance ahead of the client clock (see WithTimeFunc), which usually points
+// to a wrong clock or time zone. Unparseable dates are rejected as well.
+// Disabled by default.
+func WithRejectFutureSessionDate(tolerance time.Duration) Option {
+	return func(c *Client) {
+		c.rejectFutureSessionDate = true
+		c.futureSessionDateTolerance = tolerance
+	}
+}
+
+// WithTimeFormat sets an extra time layout, in time.Parse format, accepted for
+// timestamps such as ConversationMessage.CreatedAt and MemorizeRequest.SessionDate
+// by validation, WithRequireChronologicalMessages and WithRejectFutureSessionDate.
+// The layout is tried first. ISO 8601 (RFC 3339) and Unix epoch seconds or
+// milliseconds are always accepted as fallbacks. Values are sent unchanged.
+func WithTimeFormat(layout string) Option {
+	return func(c *Client) {
+		c.timeFormat = layout
+	}
+}
+
+// WithRetrieveFallback makes Retrieve re-run a request with fallbackQuery,
+// a string or []ConversationMessage like RetrieveRequest.Query, when the
+// primary query returns no items. The fallback results are returned with
+// RetrieveResult.Fallback set. CategoriesOnly requests are not retried.
+// Default: off.
+func WithRetrieveFallback(fallbackQuery interface{}) Option {
+	return func(c *Client) {
+		c.retrieveFallbackQuery = fallbackQuery
+	}
+}
+
+// WithTimeFunc sets the function the client uses to read the current time.
+// This is primarily useful for deterministic tests. Default: time.Now.
+func WithTimeFunc(now func() time.Time) Option {
+	return func(c *Client) {
+		c.now = now
+	}
+}
+
+// WithQueryAsConversation makes Retrieve send plain string queries as a
+// single-message conversation with the "user" role, for backends that only
+// accept conversation queries. Conversation queries are sent unchanged.
+func WithQueryAsConversation() Option {
+	return func(c *Client) {
+		c.queryAsConversation = true
+	}
+}
+
+// WithTokenRefresh sets a callback used to obtain a new API key when a request
+// fails with 401. The request is retried once with the new key. If the
+// callback fails, the original AuthenticationError is returned.
+func WithTokenRefresh(refresh func(ctx context.Context) (string, error)) Option {
+	return func(c *Client) {
+		c.tokenRefresh = refresh
+	}
+}
+
+// WithHeaderFunc sets a function that returns extra headers for each request.
+// It is evaluated on every attempt, including retries, and merged after the
+// default headers. An Authorization header returned by the function is ignored
+// unless WithAuthorizationOverride is also set.
+func WithHeaderFunc(fn func(ctx context.Context, method, path string) map[string]string) Option {
+	return func(c *Client) {
+		c.headerFunc = fn
+	}
+}
+
+// WithAuthorizationOverride allows headers returned by WithHeaderFunc to
+// replace the default Authorization header.
+func WithAuthorizationOverride() Option {
+	return func(c *Client) {
+		c.allowAuthOverride = true
+	}
+}
+
+// WithBodyRetryPredicate sets a predicate that inspects successful (2xx/3xx)
+// response bodies. When it returns true, the response is treated as a
+// transient failure and retried with the normal backoff; the retry policy
+// receives ErrTransientResponse. Once retries are exhausted the last response
+// is returned as is.
+func WithBodyRetryPredicate(predicate func(body map[string]interface{}) bool) Option {
+	return func(c *Client) {
+		c.bodyRetryPredicate = predicate
+	}
+}
+
+// WithTaskStatusMemo controls whether GetTaskStatus remembers terminal task
+// statuses (COMPLETED, SUCCESS, FAILED, CANCELLED) and returns them without a
+// network call on later lookups. Non-terminal statuses are always refetched.
+// At most DefaultTaskStatusMemoSize statuses are kept, evicting the oldest
+// first. Default: true.
+func WithTaskStatusMemo(enabled bool) Option {
+	return func(c *Client) {
+		c.taskStatusMemo = enabled
+	}
+}
+
+// WithMetrics sets a Metrics implementation that observes requests and retries.
+func WithMetrics(metrics Metrics) Option {
+	return func(c *Client) {
+		c.metrics = metrics
+	}
+}
+
+// WithCodec sets the Codec used to encode request bodies and decode responses.
+// The request size limit of WithMaxRequestBytes and WithPayloadSchemaValidation
+// use it as well. A nil codec keeps the default encoding/json codec.
+func WithCodec(codec Codec) Option {
+	return func(c *Client) {
+		if codec != nil {
+			c.codec = codec
+		}
+	}
+}
+
+// WithResponseEnvelope unwraps successful responses that a gateway wraps in an
+// envelope such as {"data": {...}, "meta": {...}}. The object under key is
+// parsed in place of the whole body; responses without it are parsed as is.
+// RawRequest and error responses are not affected.
+func WithResponseEnvelope(key string) Option {
+	return func(c *Client) {
+		c.responseEnvelope = key
+	}
+}
+
+// WithRetryableErrorMessages retries 4xx responses whose message contains any
+// of the given substrings (case-insensitive), e.g. "temporarily unavailable".
+// The retry policy is consulted with ErrTransientResponse as the error, and
+// its retry limit and backoff apply.
+func WithRetryableErrorMessages(substrings ...string) Option {
+	return func(c *Client) {
+		for _, substr := range substrings {
+			if substr != "" {
+				c.retryableErrorMessages = append(c.retryableErrorMessages, strings.ToLower(substr))
+			}
+		}
+	}
+}
+
+// WithStartupJitter delays the first request by a random duration of up to
+// max, spreading the load when many clients start at once. Requests made
+// while the delay is pending wait for it too; later requests are not delayed.
+// The wait ends early if the request context is done.
+func WithStartupJitter(max time.Duration) Option {
+	return func(c *Client) {
+		c.startupJitter = max
+	}
+}
+
+// WithAutoPaginate makes Retrieve follow the next_cursor of paginated
+// responses, combining the items of later pages until maxItems items have
+// been collected. A maxItems of 0 or less keeps the default of returning
+// only the first page.
+func WithAutoPaginate(maxItems int) Option {
+	return func(c *Client) {
+		c.autoPaginateMaxItems = maxItems
+	}
+}
+
+// WithSkipValidation bypasses the request Validate calls at the start of
+// Memorize, MemorizeBatch, ReprocessResource, ReassignMemories, Retrieve,
+// RetrieveSimilar, RecentMemories, ListCategories, ExportCategoriesJSONL and
+// GetMemoryItem, sending requests as-is. This is an advanced, unsafe option for callers that
+// already validate their requests; invalid input is rejected by the server
+// instead. Nil requests and checks enabled by other options still apply.
+func WithSkipValidation() Option {
+	return func(c *Client) {
+		c.skipValidation = true
+	}
+}
+
+// WithNormalizeIDs applies normalize to the UserID and AgentID of every
+// request before it is sent, so that IDs differing only in e.g. casing refer
+// to the same memories. Use LowercaseID for case-insensitive IDs. The
+// caller's request structs are not modified. Default: off.
+func WithNormalizeIDs(normalize func(string) string) Option {
+	return func(c *Client) {
+		c.idNormalizer = normalize
+	}
+}
+
+// WithRoleAliases rewrites conversation message roles before validation and
+// sending, for upstream systems with their own role names, e.g.
+// {"human": "user", "ai": "assistant"}. Roles without an alias pass through
+// unchanged. Applies to Memorize conversations and Retrieve message queries;
+// the caller's request structs are not modified. Default: off.
+func WithRoleAliases(aliases map[string]string) Option {
+	return func(c *Client) {
+		c.roleAliases = make(map[string]string, len(aliases))
+		for alias, role := range aliases {
+			c.roleAliases[alias] = role
+		}
+	}
+}
+
+// LowercaseID is an ID normalizer for WithNormalizeIDs that lowercases IDs.
+func LowercaseID(id string) string {
+	return strings.ToLower(id)
+}
+
+// WithTreat403AsRateLimit handles 403 responses that carry a Retry-After
+// header like 429 responses, for gateways that throttle with 403: the wait is
+// taken from Retry-After, the request is retried under the retry policy's
+// rules for 429, and a RateLimitError is returned once retries are exhausted.
+// 403 responses without Retry-After are unaffected.
+func WithTreat403AsRateLimit() Option {
+	return func(c *Client) {
+		c.treat403AsRateLimit = true
+	}
+}
+
+// WithTLSServerName sets the server name used to verify the TLS certificate,
+// e.g. when a self-hosted instance is reached by IP address but its
+// certificate is issued for a hostname. The HTTP client's transport is cloned
+// with the name applied; NewClient returns an error if a custom HTTP client
+// uses a transport other than *http.Transport.
+func WithTLSServerName(serverName string) Option {
+	return func(c *Client) {
+		c.tlsServerName = serverName
+	}
+}
+
+// WithETagCaching makes GetTaskStatus remember the ETag of each task status
+// and send it as If-None-Match on the next poll; a 304 Not Modified response
+// returns the cached status without a body. At most DefaultETagCacheSize
+// statuses are kept, evicting the oldest first.
+func WithETagCaching() Option {
+	return func(c *Client) {
+		c.etags = newETagCache(DefaultETagCacheSize)
+	}
+}
+
+// WithMethodTimeouts sets per-operation request timeouts keyed by method
+// name (e.g. "Memorize", "Retrieve", "GetTaskStatus"). Like WithTimeout, each
+// timeout bounds a single attempt; operations not in the map use the client
+// timeout. Timeouts may be longer than the client timeout.
+func WithMethodTimeouts(timeouts map[string]time.Duration) Option {
+	return func(c *Client) {
+		c.methodTimeouts = make(map[string]time.Duration, len(timeouts))
+		for operation, timeout := range timeouts {
+			c.methodTimeouts[operation] = timeout
+		}
+	}
+}
+
+// WithOnRateLimit calls fn whenever a rate limit response (429, or a 403
+// handled by WithTreat403AsRateLimit) is received, before the SDK waits to
+// retry. retryAfter is the parsed Retry-After duration, or the retry
+// policy's backoff if the header is absent. fn is also called when no retry
+// follows, and runs on the requesting goroutine, so it can apply
+// backpressure to a whole pipeline.
+func WithOnRateLimit(fn func(retryAfter time.Duration)) Option {
+	return func(c *Client) {
+		c.onRateLimit = fn
+	}
+}
+
+// WithConnectTimeout bounds how long establishing a connection, including DNS
+// resolution, may take, so unreachable hosts fail faster than slow responses.
+// The request timeout (WithTimeout or WithMethodTimeouts) still bounds the
+// whole attempt, connecting included, so a connect timeout longer than it has
+// no effect. A connect timeout is a transport timeout and is retried unless
+// WithRetryOnTimeout(false) is set. The HTTP client's transport is cloned with
+// the timeout applied; NewClient returns an error if a custom HTTP client uses
+// a transport other than *http.Transport.
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.connectTimeout = timeout
+	}
+}
+
+// WithLogger sets a logger for diagnostic messages, such as array elements
+// dropped by WithRetrieveLenient. By default nothing is logged.
+func WithLogger(logger *log.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithRetrieveLenient makes Retrieve skip categories, items and resources that
+// fail to parse instead of failing the whole call. Each skipped element is
+// logged via WithLogger and counted in RetrieveResult.DroppedElements.
+func WithRetrieveLenient() Option {
+	return func(c *Client) {
+		c.retrieveLenient = true
+	}
+}
+
+// WithContext sets the base context for background work such as
+// MemorizeAsync. Background calls are canceled when ctx is done or when
+// Shutdown cancels them. Methods taking a context use that context instead.
+func WithContext(ctx context.Context) Option {
+	return func(c *Client) {
+		if ctx != nil {
+			c.baseCtx = ctx
+		}
+	}
+}
+
+// WithTracer wraps each API call with tracer, e.g. to record tracing spans.
+// See the memuotel subpackage for OpenTelemetry.
+func WithTracer(tracer Tracer) Option {
+	return func(c *Client) {
+		c.tracer = tracer
+	}
+}
+
+// WithMaxConcurrentRequests caps the number of API calls the client has in
+// flight at once, so that a shared client cannot fan out into unbounded
+// connections and goroutines. Further calls block until a call finishes, or
+// fail with the context error if their context is done first. A call holds
+// its slot across retries. Values <= 0 disable the cap, which is the default.
+func WithMaxConcurrentRequests(max int) Option {
+	return func(c *Client) {
+		c.maxConcurrentRequests = max
+	}
+}
+
+// WithName labels the client, e.g. by tenant, to tell several clients apart.
+// The name prefixes logged lines, is passed to Metrics implementing
+// NamedMetrics, and is available to hooks through ClientName on the request
+// context. It is empty by default.
+func WithName(name string) Option {
+	return func(c *Client) {
+		c.name = name
+	}
+}
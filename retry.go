@@ -3,6 +3,8 @@
 package memu
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"time"
 )
@@ -149,3 +151,95 @@ func (p *customRetryPolicy) ShouldRetry(attempt int, statusCode int, err error)
 func (p *customRetryPolicy) GetBackoff(attempt int) time.Duration {
 	return p.getBackoff(attempt)
 }
+
+// RetryCall calls fn until it succeeds or policy gives up, for coarse
+// application-level retries around SDK calls that already failed after the
+// client's own retries. API errors are passed to policy.ShouldRetry as their
+// status code with a nil error, like HTTP responses inside the client;
+// transport and other unknown errors are passed with status code 0. The SDK's
+// deterministic client-side failures, such as request validation errors,
+// RequestTooLargeError, SchemaValidationError, ResponseValidationError and
+// UnexpectedContentTypeError, are returned at once without consulting policy,
+// as they fail the same way on every attempt. Backoff waits are at least the
+// RetryAfter of a RateLimitError. RetryCall stops with the context error if
+// ctx is done, and otherwise returns the last error of fn.
+func RetryCall[T any](ctx context.Context, policy RetryPolicy, fn func(context.Context) (T, error)) (T, error) {
+	for attempt := 0; ; attempt++ {
+		result, err := fn(ctx)
+		if err == nil {
+			return result, nil
+		}
+		if ctx.Err() != nil || isPermanentError(err) {
+			return result, err
+		}
+
+		statusCode := errorStatusCode(err)
+		retryErr := err
+		if statusCode != 0 {
+			retryErr = nil
+		}
+		if !policy.ShouldRetry(attempt, statusCode, retryErr) {
+			return result, err
+		}
+
+		backoff := policy.GetBackoff(attempt)
+		var rateLimitErr *RateLimitError
+		if errors.As(err, &rateLimitErr) && rateLimitErr.RetryAfter != nil {
+			if retryAfter := time.Duration(*rateLimitErr.RetryAfter * float64(time.Second)); retryAfter > backoff {
+				backoff = retryAfter
+			}
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// isPermanentError reports whether err is a deterministic client-side failure
+// of the SDK that retrying cannot fix.
+func isPermanentError(err error) bool {
+	var (
+		invalidErr      *invalidRequestError
+		tooLargeErr     *RequestTooLargeError
+		responseSizeErr *ResponseTooLargeError
+		schemaErr       *SchemaValidationError
+		responseErr     *ResponseValidationError
+		contentTypeErr  *UnexpectedContentTypeError
+	)
+	return errors.As(err, &invalidErr) || errors.As(err, &tooLargeErr) || errors.As(err, &responseSizeErr) ||
+		errors.As(err, &schemaErr) || errors.As(err, &responseErr) || errors.As(err, &contentTypeErr) ||
+		errors.Is(err, ErrClientShutdown)
+}
+
+// errorStatusCode returns the HTTP status code carried by an SDK API error,
+// or 0 if err is not one or has no status code.
+func errorStatusCode(err error) int {
+	var clientErr *ClientError
+	var authErr *AuthenticationError
+	var rateLimitErr *RateLimitError
+	var notFoundErr *NotFoundError
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &clientErr):
+	case errors.As(err, &authErr):
+		clientErr = authErr.ClientError
+	case errors.As(err, &rateLimitErr):
+		clientErr = rateLimitErr.ClientError
+	case errors.As(err, &notFoundErr):
+		clientErr = notFoundErr.ClientError
+	case errors.As(err, &validationErr):
+		clientErr = validationErr.ClientError
+	default:
+		return 0
+	}
+	if clientErr == nil || clientErr.StatusCode == nil {
+		return 0
+	}
+	return *clientErr.StatusCode
+}
@@ -0,0 +1,160 @@
+// Package memu provides unit tests for retry helpers.
+// This file validates application-level retries with RetryCall.
+package memu
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryCall_FailsTwiceThenSucceeds(t *testing.T) {
+	calls := 0
+	result, err := RetryCall(context.Background(), zeroBackoffPolicy(5), func(ctx context.Context) (string, error) {
+		calls++
+		if calls <= 2 {
+			return "", errors.New("connection reset")
+		}
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatalf("RetryCall failed: %v", err)
+	}
+	if result != "ok" || calls != 3 {
+		t.Errorf("expected ok after 3 calls, got %q after %d", result, calls)
+	}
+}
+
+func TestRetryCall_PolicyGivesUp(t *testing.T) {
+	calls := 0
+	_, err := RetryCall(context.Background(), zeroBackoffPolicy(2), func(ctx context.Context) (int, error) {
+		calls++
+		return 0, errors.New("still failing")
+	})
+	if err == nil || err.Error() != "still failing" {
+		t.Errorf("expected the last error, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryCall_UsesStatusCode(t *testing.T) {
+	statuses := []int{http.StatusServiceUnavailable, http.StatusNotFound}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statuses[0])
+		statuses = statuses[1:]
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithRetryPolicy(NewNoRetryPolicy()))
+	policy := NewDefaultRetryPolicy(&RetryConfig{
+		MaxRetries:           5,
+		RetryableStatusCodes: map[int]bool{http.StatusServiceUnavailable: true},
+	})
+
+	calls := 0
+	_, err := RetryCall(context.Background(), policy, func(ctx context.Context) (*TaskStatus, error) {
+		calls++
+		return client.GetTaskStatus(ctx, "t1")
+	})
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+	// 503 is retried, 404 is not
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestRetryCall_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	policy := NewCustomRetryPolicy(10,
+		func(attempt int, statusCode int, err error) bool { return true },
+		func(attempt int) time.Duration { return time.Hour },
+	)
+	_, err := RetryCall(ctx, policy, func(ctx context.Context) (int, error) {
+		return 0, errors.New("failing")
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestRetryCall_StopsOnPermanentErrors(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithMaxRequestBytes(10))
+	tests := []struct {
+		name string
+		fn   func(ctx context.Context) (*MemorizeResult, error)
+	}{
+		{"invalid request", func(ctx context.Context) (*MemorizeResult, error) {
+			return client.Memorize(ctx, &MemorizeRequest{})
+		}},
+		{"nil request", func(ctx context.Context) (*MemorizeResult, error) {
+			return client.Memorize(ctx, nil)
+		}},
+		{"request too large", func(ctx context.Context) (*MemorizeResult, error) {
+			return client.Memorize(ctx, newAsyncMemorizeRequest())
+		}},
+		{"response validation", func(ctx context.Context) (*MemorizeResult, error) {
+			return nil, NewResponseValidationError("Memorize", "empty", nil)
+		}},
+		{"unexpected content type", func(ctx context.Context) (*MemorizeResult, error) {
+			return nil, NewUnexpectedContentTypeError("text/html", http.StatusOK, "/p", "<html>")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			_, err := RetryCall(context.Background(), NewDefaultRetryPolicy(nil), func(ctx context.Context) (*MemorizeResult, error) {
+				calls++
+				return tt.fn(ctx)
+			})
+			if err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			if calls != 1 {
+				t.Errorf("expected a single call, got %d", calls)
+			}
+		})
+	}
+	if atomic.LoadInt32(&requests) != 0 {
+		t.Errorf("expected no requests to be sent, got %d", requests)
+	}
+
+	// Validation errors still read as before
+	_, err := client.Memorize(context.Background(), &MemorizeRequest{})
+	if err == nil || err.Error() != "Memorize: UserID is required" {
+		t.Errorf("unexpected validation message %v", err)
+	}
+}
+
+func TestRetryCall_StopsOnRawRequestPathError(t *testing.T) {
+	client, _ := NewClient("test_key")
+	calls := 0
+	_, err := RetryCall(context.Background(), NewDefaultRetryPolicy(nil), func(ctx context.Context) ([]byte, error) {
+		calls++
+		body, _, err := client.RawRequest(ctx, "GET", "api/v3/health", nil)
+		return body, err
+	})
+	if err == nil || err.Error() != "RawRequest: path must start with '/'" {
+		t.Fatalf("expected the path error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected a single call, got %d", calls)
+	}
+}
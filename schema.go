@@ -0,0 +1,175 @@
+// Package memu provides client-side payload schema validation for the MemU SDK.
+// This file defines a minimal JSON schema subset and the schemas for request payloads.
+package memu
+
+import (
+	"fmt"
+	"sort"
+)
+
+// payloadSchema is a minimal subset of JSON Schema used to check request payloads.
+type payloadSchema struct {
+	// Types lists the allowed JSON types (e.g., "object", "array", "string").
+	Types []string
+	// Required lists the property names that must be present on an object.
+	Required []string
+	// Properties maps property names to their schemas.
+	Properties map[string]*payloadSchema
+	// AdditionalProperties reports whether properties not listed are allowed.
+	AdditionalProperties bool
+	// Items is the schema applied to every array element.
+	Items *payloadSchema
+	// MinItems is the minimum number of array elements.
+	MinItems int
+	// MinLength is the minimum string length.
+	MinLength int
+}
+
+// conversationMessageSchema describes a single conversation message.
+var conversationMessageSchema = &payloadSchema{
+	Types:    []string{"object"},
+	Required: []string{"role", "content"},
+	Properties: map[string]*payloadSchema{
+		"role":         {Types: []string{"string"}, MinLength: 1},
+		"content":      {Types: []string{"string"}},
+		"name":         {Types: []string{"string"}},
+		"created_at":   {Types: []string{"string"}},
+		"tool_call_id": {Types: []string{"string"}},
+		"tool_name":    {Types: []string{"string"}},
+	},
+}
+
+// memorizePayloadSchema describes the payload sent to the memorize endpoint.
+var memorizePayloadSchema = &payloadSchema{
+	Types:                []string{"object"},
+	Required:             []string{"user_id", "agent_id", "user_name", "agent_name"},
+	AdditionalProperties: true,
+	Properties: map[string]*payloadSchema{
+		"user_id":           {Types: []string{"string"}, MinLength: 1},
+		"agent_id":          {Types: []string{"string"}, MinLength: 1},
+		"user_name":         {Types: []string{"string"}},
+		"agent_name":        {Types: []string{"string"}},
+		"conversation":      {Types: []string{"array"}, MinItems: 3, Items: conversationMessageSchema},
+		"conversation_text": {Types: []string{"string"}},
+		"session_date":      {Types: []string{"string"}},
+		"mode":              {Types: []string{"string"}},
+		"language":          {Types: []string{"string"}, MinLength: 2},
+	},
+}
+
+// retrievePayloadSchema describes the payload sent to the retrieve endpoint.
+var retrievePayloadSchema = &payloadSchema{
+	Types:                []string{"object"},
+	Required:             []string{"user_id", "agent_id", "query"},
+	AdditionalProperties: true,
+	Properties: map[string]*payloadSchema{
+		"user_id":         {Types: []string{"string"}, MinLength: 1},
+		"agent_id":        {Types: []string{"string"}, MinLength: 1},
+		"query":           {Types: []string{"string", "array"}, Items: conversationMessageSchema},
+		"type_weights":    {Types: []string{"object"}, AdditionalProperties: true},
+		"categories_only": {Types: []string{"boolean"}},
+		"cursor":          {Types: []string{"string"}},
+		"include_sources": {Types: []string{"boolean"}},
+		"offset":          {Types: []string{"number"}},
+		"limit":           {Types: []string{"number"}},
+		"recency_boost":   {Types: []string{"number"}},
+	},
+}
+
+// validatePayload checks a payload, as encoded by codec, against a schema and
+// returns a SchemaValidationError listing every violation found.
+func validatePayload(codec Codec, operation string, schema *payloadSchema, payload interface{}) error {
+	// Normalize the payload into generic JSON values
+	jsonBytes, err := codec.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%s: failed to marshal payload: %w", operation, err)
+	}
+	var doc interface{}
+	if err := codec.Unmarshal(jsonBytes, &doc); err != nil {
+		return fmt.Errorf("%s: failed to unmarshal payload: %w", operation, err)
+	}
+
+	var violations []string
+	schema.validate("$", doc, &violations)
+	if len(violations) > 0 {
+		return NewSchemaValidationError(operation, violations)
+	}
+	return nil
+}
+
+// validate appends violations found for value at path to violations.
+func (s *payloadSchema) validate(path string, value interface{}, violations *[]string) {
+	kind := jsonType(value)
+	if len(s.Types) > 0 && !containsString(s.Types, kind) {
+		*violations = append(*violations, fmt.Sprintf("%s: expected %v, got %s", path, s.Types, kind))
+		return
+	}
+
+	switch v := value.(type) {
+	case string:
+		if len(v) < s.MinLength {
+			*violations = append(*violations, fmt.Sprintf("%s: must be at least %d characters", path, s.MinLength))
+		}
+	case []interface{}:
+		if len(v) < s.MinItems {
+			*violations = append(*violations, fmt.Sprintf("%s: must contain at least %d items", path, s.MinItems))
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, violations)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*violations = append(*violations, fmt.Sprintf("%s.%s: is required", path, name))
+			}
+		}
+		// Sort keys so violations are reported in a stable order
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			propSchema, ok := s.Properties[key]
+			if !ok {
+				if !s.AdditionalProperties {
+					*violations = append(*violations, fmt.Sprintf("%s.%s: unknown property", path, key))
+				}
+				continue
+			}
+			propSchema.validate(path+"."+key, v[key], violations)
+		}
+	}
+}
+
+// jsonType returns the JSON type name of a decoded value.
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
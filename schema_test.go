@@ -0,0 +1,99 @@
+// Package memu provides unit tests for payload schema validation.
+// This file validates the schema checks applied before sending requests.
+package memu
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidatePayload_ValidMemorize(t *testing.T) {
+	req := &MemorizeRequest{
+		UserID:  "user_1",
+		AgentID: "agent_1",
+		Conversation: []ConversationMessage{
+			{Role: "user", Content: "Hello"},
+			{Role: "assistant", Content: "Hi"},
+			{Role: "user", Content: "Bye"},
+		},
+	}
+	if err := validatePayload(defaultCodec, "Memorize", memorizePayloadSchema, buildMemorizePayload(req)); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestValidatePayload_Violations(t *testing.T) {
+	payload := map[string]interface{}{
+		"user_id":    "",
+		"agent_name": 42,
+		"conversation": []map[string]interface{}{
+			{"role": "user", "content": "Hello"},
+			{"content": "Hi"},
+		},
+	}
+
+	err := validatePayload(defaultCodec, "Memorize", memorizePayloadSchema, payload)
+	var schemaErr *SchemaValidationError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("expected SchemaValidationError, got %v", err)
+	}
+
+	expected := []string{
+		"$.agent_id: is required",
+		"$.user_name: is required",
+		"$.agent_name: expected [string], got number",
+		"$.conversation: must contain at least 3 items",
+		"$.conversation[1].role: is required",
+		"$.user_id: must be at least 1 characters",
+	}
+	for _, want := range expected {
+		found := false
+		for _, v := range schemaErr.Violations {
+			if v == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("expected violation '%s', got %v", want, schemaErr.Violations)
+		}
+	}
+	if len(schemaErr.Violations) != len(expected) {
+		t.Errorf("expected %d violations, got %d: %v", len(expected), len(schemaErr.Violations), schemaErr.Violations)
+	}
+}
+
+func TestClient_PayloadSchemaValidation(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient("test_key", WithBaseURL(server.URL), WithPayloadSchemaValidation())
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	// An integer query passes Validate but violates the schema
+	_, err = client.Retrieve(context.Background(), &RetrieveRequest{
+		UserID:  "user_1",
+		AgentID: "agent_1",
+		Query:   42,
+	})
+	var schemaErr *SchemaValidationError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("expected SchemaValidationError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "$.query") {
+		t.Errorf("expected error to mention '$.query', got '%s'", err.Error())
+	}
+	if called {
+		t.Error("expected no HTTP request to be made")
+	}
+}
@@ -0,0 +1,83 @@
+// Package memu provides incremental memorization for the MemU SDK.
+// This file implements buffering live conversation messages and memorizing them in batches.
+package memu
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MemorizeSession buffers the messages of a live conversation and memorizes
+// them incrementally with Flush. It is safe for concurrent use.
+type MemorizeSession struct {
+	// client sends the Memorize requests.
+	client *Client
+	// userID is the user ID of every request.
+	userID string
+	// agentID is the agent ID of every request.
+	agentID string
+	// flushMu serializes Flush calls so that messages are sent once.
+	flushMu sync.Mutex
+	// mu guards messages.
+	mu sync.Mutex
+	// messages are the buffered messages not yet memorized.
+	messages []ConversationMessage
+}
+
+// StartMemorizeSession starts buffering messages for the given user and
+// agent. Nothing is sent until Flush is called.
+func (c *Client) StartMemorizeSession(userID, agentID string) *MemorizeSession {
+	return &MemorizeSession{
+		client:  c,
+		userID:  userID,
+		agentID: agentID,
+	}
+}
+
+// AddMessage appends a message to the buffer.
+func (s *MemorizeSession) AddMessage(msg ConversationMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, msg)
+}
+
+// Len returns the number of buffered messages.
+func (s *MemorizeSession) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.messages)
+}
+
+// Flush memorizes the buffered messages and, on success, removes them from
+// the buffer. Messages added while Flush runs stay buffered for the next call.
+// Flush returns an error without sending anything while fewer than 3 messages
+// are buffered, and keeps the buffer if Memorize fails so it can be retried.
+func (s *MemorizeSession) Flush(ctx context.Context) (*MemorizeResult, error) {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	pending := make([]ConversationMessage, len(s.messages))
+	copy(pending, s.messages)
+	s.mu.Unlock()
+
+	if len(pending) < minConversationMessages {
+		return nil, invalidRequest(fmt.Errorf("MemorizeSession: %d messages buffered, at least %d are needed to flush", len(pending), minConversationMessages))
+	}
+
+	result, err := s.client.Memorize(ctx, &MemorizeRequest{
+		UserID:       s.userID,
+		AgentID:      s.agentID,
+		Conversation: pending,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop only the messages that were sent
+	s.mu.Lock()
+	s.messages = append([]ConversationMessage(nil), s.messages[len(pending):]...)
+	s.mu.Unlock()
+	return result, nil
+}
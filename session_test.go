@@ -0,0 +1,93 @@
+// Package memu provides unit tests for incremental memorization.
+// This file validates MemorizeSession buffering and flushing.
+package memu
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func addSessionMessages(s *MemorizeSession, n int) {
+	for i := 0; i < n; i++ {
+		s.AddMessage(ConversationMessage{Role: "user", Content: fmt.Sprintf("message %d", s.Len())})
+	}
+}
+
+func TestMemorizeSession_FlushSendsBuffer(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"task_id":"t1","status":"PENDING"}`, &bodies)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	session := client.StartMemorizeSession("user_123", "agent_456")
+	addSessionMessages(session, 4)
+	if session.Len() != 4 {
+		t.Fatalf("expected 4 buffered messages, got %d", session.Len())
+	}
+
+	result, err := session.Flush(context.Background())
+	if err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if result.TaskID == nil || *result.TaskID != "t1" {
+		t.Errorf("expected TaskID t1, got %v", result.TaskID)
+	}
+	if session.Len() != 0 {
+		t.Errorf("expected an empty buffer after Flush, got %d", session.Len())
+	}
+	conversation, _ := bodies[0]["conversation"].([]interface{})
+	if len(conversation) != 4 || bodies[0]["user_id"] != "user_123" || bodies[0]["agent_id"] != "agent_456" {
+		t.Errorf("unexpected payload %v", bodies[0])
+	}
+
+	// The next flush only sends new messages
+	addSessionMessages(session, 3)
+	if _, err := session.Flush(context.Background()); err != nil {
+		t.Fatalf("second Flush failed: %v", err)
+	}
+	conversation, _ = bodies[1]["conversation"].([]interface{})
+	if len(conversation) != 3 || conversation[0].(map[string]interface{})["content"] != "message 0" {
+		t.Errorf("expected only the 3 new messages, got %v", conversation)
+	}
+}
+
+func TestMemorizeSession_Minimum(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newCaptureServer(`{"task_id":"t1","status":"PENDING"}`, &bodies)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	session := client.StartMemorizeSession("user_123", "agent_456")
+	addSessionMessages(session, 2)
+
+	_, err := session.Flush(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "at least 3") {
+		t.Errorf("expected minimum error, got %v", err)
+	}
+	if len(bodies) != 0 {
+		t.Errorf("expected nothing to be sent, got %d requests", len(bodies))
+	}
+	if session.Len() != 2 {
+		t.Errorf("expected the buffer to be kept, got %d messages", session.Len())
+	}
+
+	addSessionMessages(session, 1)
+	if _, err := session.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+}
+
+func TestMemorizeSession_KeepsBufferOnError(t *testing.T) {
+	client, _ := NewClient("test_key", WithBaseURL("http://127.0.0.1:1"), WithRetryPolicy(NewNoRetryPolicy()))
+	session := client.StartMemorizeSession("user_123", "agent_456")
+	addSessionMessages(session, 3)
+
+	if _, err := session.Flush(context.Background()); err == nil {
+		t.Fatal("expected Flush to fail, got nil")
+	}
+	if session.Len() != 3 {
+		t.Errorf("expected the buffer to be kept for a retry, got %d messages", session.Len())
+	}
+}
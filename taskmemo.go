@@ -0,0 +1,81 @@
+// Package memu provides the terminal task status memo for the MemU SDK.
+// This file implements the bounded cache behind WithTaskStatusMemo.
+package memu
+
+import "sync"
+
+// DefaultTaskStatusMemoSize is the maximum number of terminal task statuses
+// remembered by the memo enabled with WithTaskStatusMemo.
+const DefaultTaskStatusMemoSize = 1000
+
+// taskStatusMemo maps task IDs to their terminal status. It is safe for
+// concurrent use and evicts the oldest entry once maxEntries is reached.
+type taskStatusMemo struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*TaskStatus
+	// order lists task IDs from oldest to newest insertion.
+	order []string
+}
+
+// newTaskStatusMemo returns an empty memo holding at most maxEntries statuses.
+func newTaskStatusMemo(maxEntries int) *taskStatusMemo {
+	return &taskStatusMemo{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*TaskStatus),
+	}
+}
+
+// get returns a copy of the memoized status of taskID.
+func (m *taskStatusMemo) get(taskID string) (*TaskStatus, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status, ok := m.entries[taskID]
+	if !ok {
+		return nil, false
+	}
+	return status.clone(), true
+}
+
+// put stores a copy of status for taskID.
+func (m *taskStatusMemo) put(taskID string, status *TaskStatus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.entries[taskID]; !ok {
+		if len(m.order) >= m.maxEntries {
+			delete(m.entries, m.order[0])
+			m.order = m.order[1:]
+		}
+		m.order = append(m.order, taskID)
+	}
+	m.entries[taskID] = status.clone()
+}
+
+// clone returns a copy of s whose RawExtra shares no maps or slices with s.
+func (s *TaskStatus) clone() *TaskStatus {
+	cloned := *s
+	if s.RawExtra != nil {
+		cloned.RawExtra = copyJSONValue(s.RawExtra).(map[string]interface{})
+	}
+	return &cloned
+}
+
+// copyJSONValue deep-copies a value decoded from JSON into interface{}.
+func copyJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = copyJSONValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyJSONValue(item)
+		}
+		return copied
+	default:
+		return value
+	}
+}
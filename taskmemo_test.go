@@ -0,0 +1,56 @@
+// Package memu provides unit tests for the terminal task status memo.
+// This file validates eviction and copy semantics of memoized statuses.
+package memu
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTaskStatusMemo_Bounded(t *testing.T) {
+	memo := newTaskStatusMemo(2)
+	memo.put("a", &TaskStatus{TaskID: "a", Status: TaskStatusSuccess})
+	memo.put("b", &TaskStatus{TaskID: "b", Status: TaskStatusSuccess})
+	memo.put("a", &TaskStatus{TaskID: "a", Status: TaskStatusFailed})
+	memo.put("c", &TaskStatus{TaskID: "c", Status: TaskStatusSuccess})
+
+	if len(memo.entries) != 2 || len(memo.order) != 2 {
+		t.Errorf("expected 2 memoized entries, got %d (%d ordered)", len(memo.entries), len(memo.order))
+	}
+	if _, ok := memo.get("a"); ok {
+		t.Error("expected oldest entry 'a' to be evicted")
+	}
+	if status, ok := memo.get("c"); !ok || status.TaskID != "c" {
+		t.Errorf("expected newest entry 'c', got %+v %v", status, ok)
+	}
+}
+
+func TestClient_TaskStatusMemo_CopiesRawExtra(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"task_id":"done","status":"SUCCESS","stats":{"items":[1,2]}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	first, err := client.GetTaskStatus(ctx, "done")
+	if err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+	first.RawExtra["stats"].(map[string]interface{})["items"].([]interface{})[0] = "changed"
+	first.RawExtra["added"] = true
+
+	second, err := client.GetTaskStatus(ctx, "done")
+	if err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+	if _, ok := second.RawExtra["added"]; ok {
+		t.Error("expected memoized RawExtra to be unaffected by caller changes")
+	}
+	if got := second.RawExtra["stats"].(map[string]interface{})["items"].([]interface{})[0]; got != float64(1) {
+		t.Errorf("expected nested RawExtra value 1, got %v", got)
+	}
+}
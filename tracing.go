@@ -0,0 +1,17 @@
+// Package memu provides the tracing hook for the MemU SDK.
+// This file defines the Tracer interface used to wrap API calls, e.g. in spans.
+package memu
+
+import "context"
+
+// Tracer wraps API calls made by the client, typically in tracing spans.
+// Implementations must be safe for concurrent use.
+// See the memuotel subpackage for an OpenTelemetry implementation.
+type Tracer interface {
+	// StartOperation is called before each API call with the caller's context
+	// and the name of the client method (e.g. "Retrieve"). The returned
+	// context is used for the call, so it can carry a span for propagation.
+	// The returned function is called once after all retries complete with the
+	// last HTTP status received (0 if none) and the error, if any.
+	StartOperation(ctx context.Context, operation string) (context.Context, func(statusCode int, err error))
+}
@@ -0,0 +1,74 @@
+// Package memu provides unit tests for the tracing hook.
+// This file validates that Tracer wraps each API call.
+package memu
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type tracerCtxKey struct{}
+
+// recordingTracer records finished operations.
+type recordingTracer struct {
+	mu       sync.Mutex
+	finished []string
+	statuses []int
+	errs     []error
+}
+
+func (r *recordingTracer) StartOperation(ctx context.Context, operation string) (context.Context, func(int, error)) {
+	ctx = context.WithValue(ctx, tracerCtxKey{}, operation)
+	return ctx, func(statusCode int, err error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.finished = append(r.finished, operation)
+		r.statuses = append(r.statuses, statusCode)
+		r.errs = append(r.errs, err)
+	}
+}
+
+func TestClient_Tracer(t *testing.T) {
+	var headers []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Get("X-Operation"))
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":"bad query"}`))
+			return
+		}
+		w.Write([]byte(`{"task_id":"t1","status":"PENDING"}`))
+	}))
+	defer server.Close()
+
+	tracer := &recordingTracer{}
+	client, _ := NewClient("test_key", WithBaseURL(server.URL), WithTracer(tracer),
+		WithHeaderFunc(func(ctx context.Context, method, path string) map[string]string {
+			operation, _ := ctx.Value(tracerCtxKey{}).(string)
+			return map[string]string{"X-Operation": operation}
+		}))
+
+	if _, err := client.GetTaskStatus(context.Background(), "t1"); err != nil {
+		t.Fatalf("GetTaskStatus failed: %v", err)
+	}
+	if _, err := client.Retrieve(context.Background(), NewStringRetrieve("u1", "a1", "food")); err == nil {
+		t.Fatal("expected Retrieve to fail")
+	}
+
+	if len(tracer.finished) != 2 || tracer.finished[0] != "GetTaskStatus" || tracer.finished[1] != "Retrieve" {
+		t.Fatalf("expected GetTaskStatus and Retrieve operations, got %v", tracer.finished)
+	}
+	if tracer.statuses[0] != http.StatusOK || tracer.errs[0] != nil {
+		t.Errorf("expected 200 without error, got %d, %v", tracer.statuses[0], tracer.errs[0])
+	}
+	if tracer.statuses[1] != http.StatusBadRequest || tracer.errs[1] == nil {
+		t.Errorf("expected 400 with error, got %d, %v", tracer.statuses[1], tracer.errs[1])
+	}
+	// The context returned by the tracer is used for the request
+	if headers[0] != "GetTaskStatus" || headers[1] != "Retrieve" {
+		t.Errorf("expected the traced context to reach the request, got %v", headers)
+	}
+}
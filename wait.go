@@ -0,0 +1,122 @@
+// Package memu provides task polling helpers for the MemU SDK.
+// This file implements waiting for asynchronous memorization tasks to finish.
+package memu
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WaitOption is a function that configures WaitForTask.
+type WaitOption func(*waitConfig)
+
+// waitConfig holds the polling configuration for WaitForTask.
+type waitConfig struct {
+	// pollInterval is the interval between status checks.
+	pollInterval time.Duration
+	// maxWait is the maximum total time to wait for the task.
+	maxWait time.Duration
+	// stop ends the wait with a WaitCancelledError when closed; nil never stops.
+	stop <-chan struct{}
+}
+
+// WithPollInterval sets the interval between task status checks.
+func WithPollInterval(interval time.Duration) WaitOption {
+	return func(w *waitConfig) {
+		w.pollInterval = interval
+	}
+}
+
+// WithMaxWait sets the maximum total time to wait for the task to finish.
+func WithMaxWait(maxWait time.Duration) WaitOption {
+	return func(w *waitConfig) {
+		w.maxWait = maxWait
+	}
+}
+
+// WithStopChannel stops waiting when stop is closed, e.g. from a "stop
+// watching" button. WaitForTask then returns the last seen status with a
+// WaitCancelledError, aborting any status request in flight. It composes with
+// context cancellation: whichever happens first ends the wait.
+func WithStopChannel(stop <-chan struct{}) WaitOption {
+	return func(w *waitConfig) {
+		w.stop = stop
+	}
+}
+
+// WaitForTask polls the status of a memorization task until it reaches a
+// terminal status (COMPLETED, SUCCESS, FAILED or CANCELLED).
+//
+// The wait stops at whichever comes first of the maxWait budget and the
+// context deadline. When that happens, the last seen status is returned along
+// with a WaitTimeoutError. A FAILED task is returned without error; callers
+// should inspect Status.
+func (c *Client) WaitForTask(ctx context.Context, taskID string, opts ...WaitOption) (*TaskStatus, error) {
+	config := &waitConfig{
+		pollInterval: DefaultPollInterval,
+		maxWait:      DefaultWaitTimeout,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	// The derived context expires at min(ctx deadline, maxWait)
+	waitCtx, cancel := context.WithTimeout(ctx, config.maxWait)
+	defer cancel()
+
+	// Closing the stop channel also aborts a status request in flight
+	stopped := func() bool {
+		select {
+		case <-config.stop:
+			return true
+		default:
+			return false
+		}
+	}
+	if config.stop != nil {
+		go func() {
+			select {
+			case <-config.stop:
+				cancel()
+			case <-waitCtx.Done():
+			}
+		}()
+	}
+
+	var last *TaskStatus
+	for {
+		if stopped() {
+			return last, NewWaitCancelledError(taskID, last)
+		}
+		status, err := c.GetTaskStatus(waitCtx, taskID)
+		if err != nil {
+			if stopped() {
+				return last, NewWaitCancelledError(taskID, last)
+			}
+			if errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+				return last, NewWaitTimeoutError(taskID, last)
+			}
+			return last, err
+		}
+		last = status
+
+		if status.Status.IsTerminal() {
+			return status, nil
+		}
+
+		timer := time.NewTimer(config.pollInterval)
+		select {
+		case <-timer.C:
+		case <-waitCtx.Done():
+			timer.Stop()
+			if stopped() {
+				return last, NewWaitCancelledError(taskID, last)
+			}
+			if errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+				return last, NewWaitTimeoutError(taskID, last)
+			}
+			return last, waitCtx.Err()
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Package memu provides unit tests for task polling.
+// This file validates WaitForTask completion and deadline handling.
+package memu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTaskServer returns a server reporting PENDING until the given number of
+// polls has been made, then SUCCESS. A negative count never completes.
+func newTaskServer(completeAfter int32) *httptest.Server {
+	var polls int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&polls, 1)
+		status := "PENDING"
+		if completeAfter >= 0 && n >= completeAfter {
+			status = "SUCCESS"
+		}
+		fmt.Fprintf(w, `{"task_id":"t1","status":"%s"}`, status)
+	}))
+}
+
+func TestWaitForTask_Completes(t *testing.T) {
+	server := newTaskServer(3)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	status, err := client.WaitForTask(context.Background(), "t1", WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("WaitForTask failed: %v", err)
+	}
+	if status.Status != TaskStatusSuccess {
+		t.Errorf("expected status SUCCESS, got %s", status.Status)
+	}
+}
+
+func TestWaitForTask_MaxWait(t *testing.T) {
+	server := newTaskServer(-1)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	status, err := client.WaitForTask(context.Background(), "t1",
+		WithPollInterval(10*time.Millisecond),
+		WithMaxWait(50*time.Millisecond),
+	)
+
+	var timeoutErr *WaitTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected WaitTimeoutError, got %v", err)
+	}
+	if status == nil || status.Status != TaskStatusPending {
+		t.Errorf("expected last status PENDING, got %v", status)
+	}
+}
+
+func TestWaitForTask_ContextDeadlineShorterThanMaxWait(t *testing.T) {
+	server := newTaskServer(-1)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	status, err := client.WaitForTask(ctx, "t1",
+		WithPollInterval(10*time.Millisecond),
+		WithMaxWait(time.Minute),
+	)
+	elapsed := time.Since(start)
+
+	var timeoutErr *WaitTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected WaitTimeoutError, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("expected error to match context.DeadlineExceeded")
+	}
+	if timeoutErr.LastStatus == nil || timeoutErr.LastStatus.Status != TaskStatusPending {
+		t.Errorf("expected LastStatus PENDING, got %v", timeoutErr.LastStatus)
+	}
+	if status == nil {
+		t.Error("expected last seen status to be returned")
+	}
+	if elapsed > 5*time.Second {
+		t.Errorf("expected wait to stop at the context deadline, took %v", elapsed)
+	}
+}
+
+func TestWaitForTask_StopChannel(t *testing.T) {
+	server := newTaskServer(-1)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	stop := make(chan struct{})
+	time.AfterFunc(30*time.Millisecond, func() { close(stop) })
+
+	start := time.Now()
+	status, err := client.WaitForTask(context.Background(), "t1",
+		WithPollInterval(5*time.Millisecond),
+		WithStopChannel(stop),
+	)
+
+	var cancelledErr *WaitCancelledError
+	if !errors.As(err, &cancelledErr) {
+		t.Fatalf("expected WaitCancelledError, got %v", err)
+	}
+	if cancelledErr.TaskID != "t1" || cancelledErr.LastStatus == nil {
+		t.Errorf("expected task t1 with a last status, got %+v", cancelledErr)
+	}
+	if status == nil || status.Status != TaskStatusPending {
+		t.Errorf("expected last status PENDING, got %v", status)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected wait to stop promptly, took %v", elapsed)
+	}
+}
+
+func TestWaitForTask_StopChannelAbortsRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	stop := make(chan struct{})
+	time.AfterFunc(20*time.Millisecond, func() { close(stop) })
+
+	status, err := client.WaitForTask(context.Background(), "t1", WithStopChannel(stop))
+	var cancelledErr *WaitCancelledError
+	if !errors.As(err, &cancelledErr) {
+		t.Fatalf("expected WaitCancelledError, got %v", err)
+	}
+	if status != nil {
+		t.Errorf("expected no last status, got %v", status)
+	}
+}
+
+func TestWaitForTask_StopChannelWithContext(t *testing.T) {
+	server := newTaskServer(-1)
+	defer server.Close()
+
+	client, _ := NewClient("test_key", WithBaseURL(server.URL))
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	// The context ends the wait even though the stop channel stays open
+	_, err := client.WaitForTask(ctx, "t1", WithPollInterval(5*time.Millisecond), WithStopChannel(make(chan struct{})))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}